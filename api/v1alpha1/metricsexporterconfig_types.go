@@ -0,0 +1,164 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MetricsExporterConfigName is the name of the singleton MetricsExporterConfig read by the exporter
+const MetricsExporterConfigName = "cluster"
+
+// MetricsExporterConfigSpec defines the desired behavior of the exporter.
+// Unset fields keep the built-in defaults of the exporter.
+type MetricsExporterConfigSpec struct {
+	// WatchNamespaces are the namespaces watched for namespaced resources.
+	// Changes only take effect after a restart of the exporter.
+	// +optional
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// AggregationInterval is the interval of the aggregation loop of the metrics registry
+	// +optional
+	AggregationInterval *metav1.Duration `json:"aggregationInterval,omitempty"`
+
+	// StaleSeriesTTL is how long per-object series are exposed without being re-asserted by their controller.
+	// 0 disables the expiry of series.
+	// +optional
+	StaleSeriesTTL *metav1.Duration `json:"staleSeriesTTL,omitempty"`
+
+	// MetricsPort is the port the metrics are served on.
+	// Changes only take effect after a restart of the exporter.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	MetricsPort *int32 `json:"metricsPort,omitempty"`
+
+	// Collectors enables or disables individual collectors. Collectors are enabled unless listed as disabled.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Collectors []CollectorConfig `json:"collectors,omitempty"`
+
+	// PullSecret configures the validation of the cluster pull secret
+	// +optional
+	PullSecret *PullSecretConfig `json:"pullSecret,omitempty"`
+
+	// Machine configures the reporting of machines failing to drain
+	// +optional
+	Machine *MachineConfig `json:"machine,omitempty"`
+}
+
+// CollectorConfig enables or disables a collector
+type CollectorConfig struct {
+	// Name of the collector, e.g. "pullsecret"
+	Name string `json:"name"`
+
+	// Enabled sets whether the collector is exposed and its controller reconciles
+	Enabled bool `json:"enabled"`
+}
+
+// PullSecretConfig configures the validation of the cluster pull secret
+type PullSecretConfig struct {
+	// ExpectedRegistries are the registries the pull secret must hold credentials for.
+	// Entries are glob patterns, e.g. "*.mirror.example.com".
+	// +optional
+	ExpectedRegistries []string `json:"expectedRegistries,omitempty"`
+
+	// OptionalRegistries are the registries whose presence in the pull secret is reported
+	// without being required. Entries are glob patterns, e.g. "*.mirror.example.com".
+	// +optional
+	OptionalRegistries []string `json:"optionalRegistries,omitempty"`
+
+	// ProbeCredentials enables probing the credential of every registry of the pull secret against the
+	// Docker Registry v2 API of the registry
+	// +optional
+	ProbeCredentials bool `json:"probeCredentials,omitempty"`
+
+	// ProbeInterval is how often the credential of every registry is probed, 1h by default
+	// +optional
+	ProbeInterval *metav1.Duration `json:"probeInterval,omitempty"`
+}
+
+// MachineConfig configures the reporting of machines failing to drain
+type MachineConfig struct {
+	// DrainTimeBuffer is how long a machine has to be deleting before pods failing to drain are reported
+	// +optional
+	DrainTimeBuffer *metav1.Duration `json:"drainTimeBuffer,omitempty"`
+}
+
+// MetricsExporterConfigStatus defines the observed state of the exporter
+type MetricsExporterConfigStatus struct {
+	// ObservedGeneration is the generation of the spec last applied by the exporter
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Collectors reports the state of every collector
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Collectors []CollectorStatus `json:"collectors,omitempty"`
+}
+
+// CollectorStatus reports the state of a collector
+type CollectorStatus struct {
+	// Name of the collector
+	Name string `json:"name"`
+
+	// Active is true while the collector is exposed and its controller reconciles
+	Active bool `json:"active"`
+
+	// LastError is the last error returned by the controller of the collector
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorTime is when LastError occurred
+	// +optional
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// MetricsExporterConfig configures the osd-metrics-exporter. Only the instance named "cluster" is read.
+type MetricsExporterConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MetricsExporterConfigSpec   `json:"spec,omitempty"`
+	Status MetricsExporterConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MetricsExporterConfigList contains a list of MetricsExporterConfig
+type MetricsExporterConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricsExporterConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MetricsExporterConfig{}, &MetricsExporterConfigList{})
+}
+
+// IsCollectorEnabled returns whether the collector with the given name is enabled
+func (s *MetricsExporterConfigSpec) IsCollectorEnabled(name string) bool {
+	for _, c := range s.Collectors {
+		if c.Name == name {
+			return c.Enabled
+		}
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certinventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/configmap"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// clusterConfigName is the name of the cluster wide config resources
+	clusterConfigName = "cluster"
+	// configNamespace holds the ConfigMaps and Secrets referenced from the cluster config resources
+	configNamespace = "openshift-config"
+	// idpCAKey is the key of the CA bundle in ConfigMaps referenced by identity providers
+	idpCAKey = "ca.crt"
+)
+
+var log = logf.Log.WithName("controller_certinventory")
+
+// CertificateInventoryReconciler takes the inventory of every certificate referenced from the OAuth,
+// APIServer and Image cluster config resources
+type CertificateInventoryReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
+}
+
+// Reconcile resolves every certificate reference of the cluster config resources. Every request
+// takes the full inventory, as the referenced objects are shared between the resources.
+func (r *CertificateInventoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling certificate inventory")
+
+	var references []Reference
+	oauth := &configv1.OAuth{}
+	found, err := r.getClusterConfig(ctx, oauth)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if found {
+		refs, err := r.oauthReferences(ctx, oauth)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		references = append(references, refs...)
+	}
+
+	apiServer := &configv1.APIServer{}
+	found, err = r.getClusterConfig(ctx, apiServer)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if found {
+		refs, err := r.apiServerReferences(ctx, apiServer)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		references = append(references, refs...)
+	}
+
+	image := &configv1.Image{}
+	found, err = r.getClusterConfig(ctx, image)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if found {
+		refs, err := r.imageReferences(ctx, image)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		references = append(references, refs...)
+	}
+
+	for _, ref := range references {
+		if !ref.Valid {
+			reqLogger.Info("Invalid certificate reference", "resource", ref.Resource, "fieldPath", ref.FieldPath,
+				"object", ref.Object, "key", ref.Key)
+		}
+	}
+	r.Metrics.SetCertificateReferences(r.ClusterId, references)
+	return ctrl.Result{}, nil
+}
+
+// getClusterConfig fetches the cluster wide instance of obj, returning false if there is none
+func (r *CertificateInventoryReconciler) getClusterConfig(ctx context.Context, obj client.Object) (bool, error) {
+	err := r.Get(ctx, types.NamespacedName{Name: clusterConfigName}, obj)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *CertificateInventoryReconciler) oauthReferences(ctx context.Context, oauth *configv1.OAuth) ([]Reference, error) {
+	resource := "oauth/" + oauth.Name
+	var references []Reference
+	for i, idp := range oauth.Spec.IdentityProviders {
+		provider, ca := identityProviderCA(idp.IdentityProviderConfig)
+		if ca.Name == "" {
+			continue
+		}
+		fieldPath := fmt.Sprintf("spec.identityProviders[%d].%s.ca", i, provider)
+		refs, err := r.configMapReferences(ctx, resource, fieldPath, ca.Name, idpCAKey)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, refs...)
+	}
+	return references, nil
+}
+
+// identityProviderCA returns the json name of the configured provider and its CA reference, an empty
+// reference if the provider has none
+func identityProviderCA(idp configv1.IdentityProviderConfig) (string, configv1.ConfigMapNameReference) {
+	switch {
+	case idp.BasicAuth != nil:
+		return "basicAuth", idp.BasicAuth.CA
+	case idp.GitHub != nil:
+		return "github", idp.GitHub.CA
+	case idp.GitLab != nil:
+		return "gitlab", idp.GitLab.CA
+	case idp.Keystone != nil:
+		return "keystone", idp.Keystone.CA
+	case idp.LDAP != nil:
+		return "ldap", idp.LDAP.CA
+	case idp.OpenID != nil:
+		return "openID", idp.OpenID.CA
+	case idp.RequestHeader != nil:
+		return "requestHeader", idp.RequestHeader.ClientCA
+	}
+	return "", configv1.ConfigMapNameReference{}
+}
+
+func (r *CertificateInventoryReconciler) apiServerReferences(ctx context.Context, apiServer *configv1.APIServer) ([]Reference, error) {
+	resource := "apiserver/" + apiServer.Name
+	var references []Reference
+	for i, namedCertificate := range apiServer.Spec.ServingCerts.NamedCertificates {
+		if namedCertificate.ServingCertificate.Name == "" {
+			continue
+		}
+		ref := Reference{
+			Resource:  resource,
+			FieldPath: fmt.Sprintf("spec.servingCerts.namedCertificates[%d].servingCertificate", i),
+			Object:    fmt.Sprintf("secret/%s/%s", configNamespace, namedCertificate.ServingCertificate.Name),
+			Key:       corev1.TLSCertKey,
+		}
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: configNamespace, Name: namedCertificate.ServingCertificate.Name}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			setCertificates(&ref, secret.Data[corev1.TLSCertKey])
+		}
+		references = append(references, ref)
+	}
+	return references, nil
+}
+
+func (r *CertificateInventoryReconciler) imageReferences(ctx context.Context, image *configv1.Image) ([]Reference, error) {
+	if image.Spec.AdditionalTrustedCA.Name == "" {
+		return nil, nil
+	}
+	// Every key of the ConfigMap is the CA bundle of a registry
+	return r.configMapReferences(ctx, "image/"+image.Name, "spec.additionalTrustedCA", image.Spec.AdditionalTrustedCA.Name, "")
+}
+
+// configMapReferences returns the references to the ConfigMap name in the config namespace. It returns a
+// reference to key, or one reference to every key of the ConfigMap if key is empty.
+func (r *CertificateInventoryReconciler) configMapReferences(ctx context.Context, resource, fieldPath, name, key string) ([]Reference, error) {
+	object := fmt.Sprintf("configmap/%s/%s", configNamespace, name)
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: configNamespace, Name: name}, cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return []Reference{{Resource: resource, FieldPath: fieldPath, Object: object, Key: key}}, nil
+		}
+		return nil, err
+	}
+	keys := []string{key}
+	if key == "" {
+		keys = make([]string, 0, len(cm.Data))
+		for k := range cm.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+	references := make([]Reference, 0, len(keys))
+	for _, k := range keys {
+		ref := Reference{Resource: resource, FieldPath: fieldPath, Object: object, Key: k}
+		setCertificates(&ref, []byte(cm.Data[k]))
+		references = append(references, ref)
+	}
+	return references, nil
+}
+
+// setCertificates sets the certificates of ref to those held by data
+func setCertificates(ref *Reference, data []byte) {
+	bundle := configmap.ParseBundle(data)
+	ref.Certificates = bundle.Certificates
+	ref.Valid = bundle.WellFormed()
+}
+
+// SetupWithManager sets up the controller with the Manager. Changes of the cluster config resources and
+// of any ConfigMap or Secret in the config namespace trigger a new inventory.
+func (r *CertificateInventoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inventory := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: clusterConfigName}}}
+	})
+	inConfigNamespace := builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == configNamespace
+	}))
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(collectorName).
+		Watches(&configv1.OAuth{}, inventory).
+		Watches(&configv1.APIServer{}, inventory).
+		Watches(&configv1.Image{}, inventory).
+		Watches(&corev1.ConfigMap{}, inventory, inConfigNamespace).
+		Watches(&corev1.Secret{}, inventory, inConfigNamespace).
+		WatchesRawSource(r.Metrics.ResyncSource(&configv1.APIServer{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
+}
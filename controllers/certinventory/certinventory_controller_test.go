@@ -0,0 +1,160 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certinventory
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/configmap"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "test-cluster-id"
+
+// makeTestCertificate creates a self-signed certificate named name which expires at notAfter
+func makeTestCertificate(t *testing.T, name string, notAfter time.Time) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func encodeTestCertificate(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+func reconcileInventory(t *testing.T, objects ...client.Object) *Collector {
+	require.NoError(t, configv1.Install(scheme.Scheme))
+	collector := NewCollector()
+	reconciler := CertificateInventoryReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+		Metrics:   collector,
+		ClusterId: testClusterId,
+	}
+	_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: clusterConfigName}})
+	require.NoError(t, err)
+	return collector
+}
+
+func TestReconcileCertificateInventory_Reconcile(t *testing.T) {
+	idpCA := makeTestCertificate(t, "idp", time.Unix(2000000000, 0))
+	serving := makeTestCertificate(t, "api.example.com", time.Unix(2100000000, 0))
+	registryCA := makeTestCertificate(t, "registry", time.Unix(2200000000, 0))
+
+	oauth := &configv1.OAuth{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+		Spec: configv1.OAuthSpec{IdentityProviders: []configv1.IdentityProvider{
+			{
+				Name: "htpasswd",
+				IdentityProviderConfig: configv1.IdentityProviderConfig{
+					Type:     configv1.IdentityProviderTypeHTPasswd,
+					HTPasswd: &configv1.HTPasswdIdentityProvider{},
+				},
+			},
+			{
+				Name: "sso",
+				IdentityProviderConfig: configv1.IdentityProviderConfig{
+					Type:   configv1.IdentityProviderTypeOpenID,
+					OpenID: &configv1.OpenIDIdentityProvider{CA: configv1.ConfigMapNameReference{Name: "idp-ca"}},
+				},
+			},
+			{
+				Name: "ldap",
+				IdentityProviderConfig: configv1.IdentityProviderConfig{
+					Type: configv1.IdentityProviderTypeLDAP,
+					LDAP: &configv1.LDAPIdentityProvider{CA: configv1.ConfigMapNameReference{Name: "missing-ca"}},
+				},
+			},
+		}},
+	}
+	apiServer := &configv1.APIServer{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+		Spec: configv1.APIServerSpec{ServingCerts: configv1.APIServerServingCerts{
+			NamedCertificates: []configv1.APIServerNamedServingCert{
+				{ServingCertificate: configv1.SecretNameReference{Name: "api-cert"}},
+			},
+		}},
+	}
+	image := &configv1.Image{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+		Spec:       configv1.ImageSpec{AdditionalTrustedCA: configv1.ConfigMapNameReference{Name: "registry-cas"}},
+	}
+	idpConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "idp-ca", Namespace: configNamespace},
+		Data:       map[string]string{idpCAKey: encodeTestCertificate(idpCA)},
+	}
+	servingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-cert", Namespace: configNamespace},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte(encodeTestCertificate(serving))},
+	}
+	registryConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-cas", Namespace: configNamespace},
+		Data: map[string]string{
+			"registry.example.com..5000": encodeTestCertificate(registryCA),
+			"broken.example.com":         "derp",
+		},
+	}
+
+	collector := reconcileInventory(t, oauth, apiServer, image, idpConfigMap, servingSecret, registryConfigMap)
+
+	expected := fmt.Sprintf(`
+# HELP cluster_config_certificate_expiry_timestamp Unix timestamp in UTC at which a certificate referenced from a cluster config resource expires
+# TYPE cluster_config_certificate_expiry_timestamp gauge
+cluster_config_certificate_expiry_timestamp{_id="test-cluster-id",field_path="spec.additionalTrustedCA",fingerprint="%s",key="registry.example.com..5000",name="osd_exporter",object="configmap/openshift-config/registry-cas",resource="image/cluster",subject="CN=registry"} 2.2e+09
+cluster_config_certificate_expiry_timestamp{_id="test-cluster-id",field_path="spec.identityProviders[1].openID.ca",fingerprint="%s",key="ca.crt",name="osd_exporter",object="configmap/openshift-config/idp-ca",resource="oauth/cluster",subject="CN=idp"} 2e+09
+cluster_config_certificate_expiry_timestamp{_id="test-cluster-id",field_path="spec.servingCerts.namedCertificates[0].servingCertificate",fingerprint="%s",key="tls.crt",name="osd_exporter",object="secret/openshift-config/api-cert",resource="apiserver/cluster",subject="CN=api.example.com"} 2.1e+09
+# HELP cluster_config_certificate_reference_valid Indicates if a certificate reference of a cluster config resource resolves to valid certificates
+# TYPE cluster_config_certificate_reference_valid gauge
+cluster_config_certificate_reference_valid{_id="test-cluster-id",field_path="spec.additionalTrustedCA",key="broken.example.com",name="osd_exporter",object="configmap/openshift-config/registry-cas",resource="image/cluster"} 0
+cluster_config_certificate_reference_valid{_id="test-cluster-id",field_path="spec.additionalTrustedCA",key="registry.example.com..5000",name="osd_exporter",object="configmap/openshift-config/registry-cas",resource="image/cluster"} 1
+cluster_config_certificate_reference_valid{_id="test-cluster-id",field_path="spec.identityProviders[1].openID.ca",key="ca.crt",name="osd_exporter",object="configmap/openshift-config/idp-ca",resource="oauth/cluster"} 1
+cluster_config_certificate_reference_valid{_id="test-cluster-id",field_path="spec.identityProviders[2].ldap.ca",key="ca.crt",name="osd_exporter",object="configmap/openshift-config/missing-ca",resource="oauth/cluster"} 0
+cluster_config_certificate_reference_valid{_id="test-cluster-id",field_path="spec.servingCerts.namedCertificates[0].servingCertificate",key="tls.crt",name="osd_exporter",object="secret/openshift-config/api-cert",resource="apiserver/cluster"} 1
+`, configmap.Fingerprint(registryCA), configmap.Fingerprint(idpCA), configmap.Fingerprint(serving))
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
+
+func TestReconcileCertificateInventoryWithoutConfig_Reconcile(t *testing.T) {
+	collector := reconcileInventory(t)
+	require.Equal(t, 0, testutil.CollectAndCount(collector))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certinventory
+
+import (
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/configmap"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName = "certinventory"
+
+	resourceLabel    = "resource"
+	fieldPathLabel   = "field_path"
+	objectLabel      = "object"
+	keyLabel         = "key"
+	fingerprintLabel = "fingerprint"
+	subjectLabel     = "subject"
+)
+
+var (
+	clusterConfigCertificateExpiryDesc = metrics.NewDesc(
+		"cluster_config_certificate_expiry_timestamp",
+		"Unix timestamp in UTC at which a certificate referenced from a cluster config resource expires",
+		metrics.ClusterIDLabel, resourceLabel, fieldPathLabel, objectLabel, keyLabel, fingerprintLabel, subjectLabel,
+	)
+	clusterConfigCertificateReferenceValidDesc = metrics.NewDesc(
+		"cluster_config_certificate_reference_valid",
+		"Indicates if a certificate reference of a cluster config resource resolves to valid certificates",
+		metrics.ClusterIDLabel, resourceLabel, fieldPathLabel, objectLabel, keyLabel,
+	)
+)
+
+// Reference is a key of a ConfigMap or Secret holding certificates, referenced from a field of a
+// cluster config resource
+type Reference struct {
+	// Resource is the referencing resource, e.g. "oauth/cluster"
+	Resource string
+	// FieldPath is the path of the referencing field, e.g. "spec.identityProviders[0].openID.ca"
+	FieldPath string
+	// Object is the referenced object, e.g. "configmap/openshift-config/idp-ca"
+	Object string
+	Key    string
+	// Valid is true if the key exists and every PEM block of it holds certificates
+	Valid        bool
+	Certificates []*x509.Certificate
+}
+
+// certificate is what is reported on a single certificate of a reference
+type certificate struct {
+	fingerprint string
+	subject     string
+	notAfter    time.Time
+}
+
+// reference is the last observed state of a Reference
+type reference struct {
+	resource     string
+	fieldPath    string
+	object       string
+	key          string
+	valid        bool
+	certificates map[string]certificate
+}
+
+// Collector exposes the expiry of every certificate referenced from the cluster config resources
+type Collector struct {
+	metrics.ReconcileStatus
+
+	clusterId string
+	// references is nil until the inventory has been taken
+	references []reference
+	mutex      sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterConfigCertificateExpiryDesc
+	ch <- clusterConfigCertificateReferenceValidDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, ref := range c.references {
+		ch <- metrics.NewGauge(clusterConfigCertificateReferenceValidDesc, metrics.BoolToFloat64(ref.valid),
+			c.clusterId, ref.resource, ref.fieldPath, ref.object, ref.key)
+		for _, cert := range ref.certificates {
+			ch <- metrics.NewGauge(clusterConfigCertificateExpiryDesc, float64(cert.notAfter.UTC().Unix()),
+				c.clusterId, ref.resource, ref.fieldPath, ref.object, ref.key, cert.fingerprint, cert.subject)
+		}
+	}
+}
+
+// SetCertificateReferences replaces the inventory of referenced certificates. References which are
+// no longer part of the inventory stop being reported. Certificates held more than once by the same
+// reference are reported once.
+func (c *Collector) SetCertificateReferences(uuid string, references []Reference) {
+	refs := make([]reference, 0, len(references))
+	for _, r := range references {
+		ref := reference{
+			resource:     r.Resource,
+			fieldPath:    r.FieldPath,
+			object:       r.Object,
+			key:          r.Key,
+			valid:        r.Valid,
+			certificates: make(map[string]certificate, len(r.Certificates)),
+		}
+		for _, cert := range r.Certificates {
+			fp := configmap.Fingerprint(cert)
+			ref.certificates[fp] = certificate{
+				fingerprint: fp,
+				subject:     cert.Subject.String(),
+				notAfter:    cert.NotAfter,
+			}
+		}
+		refs = append(refs, ref)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterId = uuid
+	c.references = refs
+}
+
+// Reset forgets the inventory of referenced certificates
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.references = nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+const (
+	pemBeginMarker         = "-----BEGIN "
+	pemEndMarker           = "-----END "
+	pemCertificateType     = "CERTIFICATE"
+	blockReasonMalformed   = "malformed_pem"
+	blockReasonNotCert     = "not_certificate"
+	blockReasonInvalidCert = "invalid_certificate"
+)
+
+// blockReasons are the reasons PEM blocks of the bundle are rejected for
+var blockReasons = []string{blockReasonMalformed, blockReasonNotCert, blockReasonInvalidCert}
+
+// InvalidBlock is a PEM block of the bundle which does not hold any certificate
+type InvalidBlock struct {
+	// Index is the position of the block in the bundle, starting at 0
+	Index  int
+	Reason string
+	Err    error
+}
+
+// Bundle is the result of parsing every PEM block of a CA bundle independently
+type Bundle struct {
+	Certificates  []*x509.Certificate
+	ValidBlocks   int
+	InvalidBlocks []InvalidBlock
+}
+
+// WellFormed returns whether every block of the bundle holds certificates
+func (b *Bundle) WellFormed() bool {
+	return len(b.InvalidBlocks) == 0 && len(b.Certificates) > 0
+}
+
+// Valid returns whether the bundle is well formed and every certificate of it is valid at now,
+// i.e. neither expired nor not yet valid
+func (b *Bundle) Valid(now time.Time) bool {
+	if !b.WellFormed() {
+		return false
+	}
+	for _, cert := range b.Certificates {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseBundle parses every PEM block of data on its own, so that an invalid block does not hide
+// the certificates of the other blocks. Text outside of PEM blocks, e.g. comments, is ignored,
+// but data without any PEM block is malformed.
+func ParseBundle(data []byte) *Bundle {
+	bundle := &Bundle{}
+	blocks := splitPEMBlocks(data)
+	if len(blocks) == 0 && len(bytes.TrimSpace(data)) > 0 {
+		bundle.InvalidBlocks = append(bundle.InvalidBlocks, InvalidBlock{
+			Reason: blockReasonMalformed,
+			Err:    fmt.Errorf("no PEM block found"),
+		})
+		return bundle
+	}
+	for i, block := range blocks {
+		decoded, _ := pem.Decode(block)
+		if decoded == nil {
+			bundle.InvalidBlocks = append(bundle.InvalidBlocks, InvalidBlock{
+				Index:  i,
+				Reason: blockReasonMalformed,
+				Err:    fmt.Errorf("failed to decode PEM block"),
+			})
+			continue
+		}
+		if decoded.Type != pemCertificateType {
+			bundle.InvalidBlocks = append(bundle.InvalidBlocks, InvalidBlock{
+				Index:  i,
+				Reason: blockReasonNotCert,
+				Err:    fmt.Errorf("unexpected PEM block type %q", decoded.Type),
+			})
+			continue
+		}
+		certificates, err := x509.ParseCertificates(decoded.Bytes)
+		if err != nil {
+			bundle.InvalidBlocks = append(bundle.InvalidBlocks, InvalidBlock{
+				Index:  i,
+				Reason: blockReasonInvalidCert,
+				Err:    err,
+			})
+			continue
+		}
+		bundle.Certificates = append(bundle.Certificates, certificates...)
+		bundle.ValidBlocks++
+	}
+	return bundle
+}
+
+// splitPEMBlocks returns every PEM block of data from its BEGIN line to the end of its END line.
+// A block without END line extends up to the next BEGIN line, so that it fails to decode on its own
+// instead of swallowing the next block.
+func splitPEMBlocks(data []byte) [][]byte {
+	var blocks [][]byte
+	for {
+		begin := bytes.Index(data, []byte(pemBeginMarker))
+		if begin < 0 {
+			return blocks
+		}
+		data = data[begin:]
+		rest := data[len(pemBeginMarker):]
+		next := bytes.Index(rest, []byte(pemBeginMarker))
+		end := bytes.Index(rest, []byte(pemEndMarker))
+		if end < 0 || (next >= 0 && next < end) {
+			stop := len(data)
+			if next >= 0 {
+				stop = len(pemBeginMarker) + next
+			}
+			blocks = append(blocks, data[:stop])
+			data = data[stop:]
+			continue
+		}
+		stop := len(data)
+		endLine := len(pemBeginMarker) + end
+		if eol := bytes.IndexByte(data[endLine:], '\n'); eol >= 0 {
+			stop = endLine + eol + 1
+		}
+		blocks = append(blocks, data[:stop])
+		data = data[stop:]
+	}
+}
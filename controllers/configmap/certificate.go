@@ -0,0 +1,92 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"time"
+)
+
+// weakSignatureAlgorithms are the signature algorithms relying on broken hash functions
+var weakSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.MD2WithRSA:    true,
+	x509.MD5WithRSA:    true,
+	x509.SHA1WithRSA:   true,
+	x509.DSAWithSHA1:   true,
+	x509.ECDSAWithSHA1: true,
+}
+
+// certificate is what is reported on a single certificate of the bundle
+type certificate struct {
+	fingerprint        string
+	subject            string
+	notBefore          time.Time
+	notAfter           time.Time
+	keyAlgorithm       string
+	keySize            int
+	signatureAlgorithm string
+	isCA               bool
+	selfSigned         bool
+	weakSignature      bool
+}
+
+func newCertificate(cert *x509.Certificate) certificate {
+	return certificate{
+		fingerprint:        Fingerprint(cert),
+		subject:            cert.Subject.String(),
+		notBefore:          cert.NotBefore,
+		notAfter:           cert.NotAfter,
+		keyAlgorithm:       cert.PublicKeyAlgorithm.String(),
+		keySize:            publicKeySize(cert.PublicKey),
+		signatureAlgorithm: cert.SignatureAlgorithm.String(),
+		isCA:               cert.IsCA,
+		selfSigned:         isSelfSigned(cert),
+		weakSignature:      weakSignatureAlgorithms[cert.SignatureAlgorithm],
+	}
+}
+
+// Fingerprint returns the hex encoded SHA-256 digest of the DER encoding of cert, which labels the
+// certificates reported by every collector
+func Fingerprint(cert *x509.Certificate) string {
+	digest := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(digest[:])
+}
+
+// publicKeySize returns the size of key in bits, 0 if the key type is unknown
+func publicKeySize(key any) int {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen()
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	}
+	return 0
+}
+
+// isSelfSigned returns whether cert is issued by itself. The signature is not verified, as
+// weak signature algorithms are rejected by the x509 package.
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+		return false
+	}
+	return len(cert.AuthorityKeyId) == 0 || bytes.Equal(cert.AuthorityKeyId, cert.SubjectKeyId)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"bytes"
+	"crypto/x509"
+)
+
+const (
+	// chainIssueOrphanIntermediate is a CA certificate whose issuer is not part of the bundle
+	chainIssueOrphanIntermediate = "orphan_intermediate"
+	// chainIssueDuplicate is a certificate which is part of the bundle more than once
+	chainIssueDuplicate = "duplicate"
+	// chainIssueOutlivesIssuer is a certificate which expires after every issuer of the bundle,
+	// so that its chain is broken before the certificate itself expires
+	chainIssueOutlivesIssuer = "outlives_issuer"
+)
+
+// chainIssueReasons are the reasons certificates of the bundle are reported by the chain analysis for
+var chainIssueReasons = []string{chainIssueOrphanIntermediate, chainIssueDuplicate, chainIssueOutlivesIssuer}
+
+// chainIssueMessages are logged for every chain issue, telling what to fix in the bundle
+var chainIssueMessages = map[string]string{
+	chainIssueOrphanIntermediate: "Intermediate certificate in user-ca-bundle without its issuer, add the issuing CA",
+	chainIssueDuplicate:          "Certificate in user-ca-bundle more than once, remove the extra copies",
+	chainIssueOutlivesIssuer:     "Certificate in user-ca-bundle expires after its issuer, renew the issuing CA",
+}
+
+// chainIssue is a problem with how a certificate fits into the chains built from the bundle
+type chainIssue struct {
+	reason      string
+	fingerprint string
+	subject     string
+	// issuer is the subject of the issuer of the certificate
+	issuer string
+	// copies is the number of times the certificate is part of the bundle
+	copies int
+}
+
+// chainIssues builds chains from the certificates of the bundle and returns the orphan
+// intermediates, the duplicates and the certificates outliving their issuers. Issuers are
+// matched by name and key identifier only, as the x509 package rejects weak signatures.
+func (b *Bundle) chainIssues() []chainIssue {
+	var issues []chainIssue
+	var unique []*x509.Certificate
+	copies := map[string]int{}
+	for _, cert := range b.Certificates {
+		fp := Fingerprint(cert)
+		if copies[fp] == 0 {
+			unique = append(unique, cert)
+		}
+		copies[fp]++
+	}
+	for _, cert := range unique {
+		if n := copies[Fingerprint(cert)]; n > 1 {
+			issues = append(issues, newChainIssue(chainIssueDuplicate, cert, n))
+		}
+	}
+
+	for _, cert := range unique {
+		if isSelfSigned(cert) {
+			continue
+		}
+		var issuer *x509.Certificate
+		for _, candidate := range unique {
+			if candidate == cert || !isIssuedBy(cert, candidate) {
+				continue
+			}
+			// The latest expiring issuer bounds the lifetime of the chain
+			if issuer == nil || candidate.NotAfter.After(issuer.NotAfter) {
+				issuer = candidate
+			}
+		}
+		if issuer == nil {
+			if cert.IsCA {
+				issues = append(issues, newChainIssue(chainIssueOrphanIntermediate, cert, 1))
+			}
+			continue
+		}
+		if issuer.NotAfter.Before(cert.NotAfter) {
+			issue := newChainIssue(chainIssueOutlivesIssuer, cert, 1)
+			issue.issuer = issuer.Subject.String()
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func newChainIssue(reason string, cert *x509.Certificate, copies int) chainIssue {
+	return chainIssue{
+		reason:      reason,
+		fingerprint: Fingerprint(cert),
+		subject:     cert.Subject.String(),
+		issuer:      cert.Issuer.String(),
+		copies:      copies,
+	}
+}
+
+// isIssuedBy returns whether issuer is named as the issuer of cert, and its key identifier
+// matches the authority key identifier of cert when both are present
+func isIssuedBy(cert, issuer *x509.Certificate) bool {
+	if !issuer.IsCA || !bytes.Equal(cert.RawIssuer, issuer.RawSubject) {
+		return false
+	}
+	if len(cert.AuthorityKeyId) == 0 || len(issuer.SubjectKeyId) == 0 {
+		return true
+	}
+	return bytes.Equal(cert.AuthorityKeyId, issuer.SubjectKeyId)
+}
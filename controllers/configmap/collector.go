@@ -0,0 +1,267 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName       = "configmap"
+	proxyCAValidMetric  = "cluster_proxy_ca_valid"
+	proxyCASubjectLabel = "subject"
+
+	fingerprintLabel        = "fingerprint"
+	keyAlgorithmLabel       = "key_algorithm"
+	keySizeLabel            = "key_size"
+	signatureAlgorithmLabel = "signature_algorithm"
+	isCALabel               = "is_ca"
+	selfSignedLabel         = "self_signed"
+	reasonLabel             = "reason"
+
+	// Reasons certificates are flagged for
+	reasonExpired       = "expired"
+	reasonNotYetValid   = "not_yet_valid"
+	reasonWeakSignature = "weak_signature"
+)
+
+var (
+	clusterProxyCAExpiryDesc = metrics.NewDesc(
+		"cluster_proxy_ca_expiry_timestamp",
+		"Indicates cluster proxy CA expiry unix timestamp in UTC",
+		metrics.ClusterIDLabel, proxyCASubjectLabel,
+	)
+	clusterProxyCAValidDesc = metrics.NewDesc(
+		proxyCAValidMetric,
+		"Indicates if cluster proxy CA valid",
+		metrics.ClusterIDLabel,
+	)
+	clusterProxyCAValidTransitionDesc = metrics.NewTransitionDesc(proxyCAValidMetric, metrics.ClusterIDLabel)
+
+	clusterProxyCACertificateNotBeforeDesc = metrics.NewDesc(
+		"cluster_proxy_ca_certificate_not_before_timestamp",
+		"Unix timestamp in UTC from which a certificate of the cluster proxy CA bundle is valid",
+		metrics.ClusterIDLabel, fingerprintLabel, proxyCASubjectLabel,
+	)
+	clusterProxyCACertificateDaysUntilExpiryDesc = metrics.NewDesc(
+		"cluster_proxy_ca_certificate_days_until_expiry",
+		"Days until a certificate of the cluster proxy CA bundle expires, negative once expired",
+		metrics.ClusterIDLabel, fingerprintLabel, proxyCASubjectLabel,
+	)
+	clusterProxyCACertificateInfoDesc = metrics.NewDesc(
+		"cluster_proxy_ca_certificate_info",
+		"Key, signature and kind of a certificate of the cluster proxy CA bundle",
+		metrics.ClusterIDLabel, fingerprintLabel, proxyCASubjectLabel,
+		keyAlgorithmLabel, keySizeLabel, signatureAlgorithmLabel, isCALabel, selfSignedLabel,
+	)
+	clusterProxyCAValidBlocksDesc = metrics.NewDesc(
+		"cluster_proxy_ca_valid_blocks",
+		"Number of PEM blocks of the cluster proxy CA bundle holding certificates",
+		metrics.ClusterIDLabel,
+	)
+	clusterProxyCAInvalidBlocksDesc = metrics.NewDesc(
+		"cluster_proxy_ca_invalid_blocks",
+		"Number of PEM blocks of the cluster proxy CA bundle which were rejected, by reason",
+		metrics.ClusterIDLabel, reasonLabel,
+	)
+	clusterProxyCAFlaggedCertificatesDesc = metrics.NewDesc(
+		"cluster_proxy_ca_flagged_certificates",
+		"Number of certificates of the cluster proxy CA bundle which are expired, not yet valid or use a weak signature",
+		metrics.ClusterIDLabel, reasonLabel,
+	)
+	clusterProxyCAChainIssuesDesc = metrics.NewDesc(
+		"cluster_proxy_ca_chain_issues",
+		"Number of certificates of the cluster proxy CA bundle which are orphan intermediates, duplicates or outlive their issuer",
+		metrics.ClusterIDLabel, reasonLabel,
+	)
+	clusterProxyCACertificateChainIssueDesc = metrics.NewDesc(
+		"cluster_proxy_ca_certificate_chain_issue",
+		"Indicates a certificate of the cluster proxy CA bundle reported by the chain analysis, by reason",
+		metrics.ClusterIDLabel, fingerprintLabel, proxyCASubjectLabel, reasonLabel,
+	)
+)
+
+// caBundle is the last observed state of the user-ca-bundle
+type caBundle struct {
+	clusterId string
+	valid     bool
+	// expiries holds the NotAfter of every certificate of the bundle by subject
+	expiries map[string]time.Time
+	// certificates holds the certificates of the bundle by fingerprint
+	certificates map[string]certificate
+	validBlocks  int
+	// invalidBlocks holds the number of rejected PEM blocks by reason
+	invalidBlocks map[string]int
+	chainIssues   []chainIssue
+	// lastConfirmed is the last time the reconciler observed the bundle
+	lastConfirmed time.Time
+}
+
+// Collector exposes the expiry and the validity of the cluster proxy CA bundle
+type Collector struct {
+	metrics.ReconcileStatus
+
+	// bundle is nil while there is no CA bundle to report on
+	bundle *caBundle
+	// valid tracks the transitions of bundle.valid while there is a bundle
+	valid metrics.BoolTransition
+	mutex sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Sweeper   = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterProxyCAExpiryDesc
+	ch <- clusterProxyCAValidDesc
+	ch <- clusterProxyCAValidTransitionDesc
+	ch <- clusterProxyCACertificateNotBeforeDesc
+	ch <- clusterProxyCACertificateDaysUntilExpiryDesc
+	ch <- clusterProxyCACertificateInfoDesc
+	ch <- clusterProxyCAValidBlocksDesc
+	ch <- clusterProxyCAInvalidBlocksDesc
+	ch <- clusterProxyCAFlaggedCertificatesDesc
+	ch <- clusterProxyCAChainIssuesDesc
+	ch <- clusterProxyCACertificateChainIssueDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.bundle == nil {
+		return
+	}
+	for subject, notAfter := range c.bundle.expiries {
+		ch <- metrics.NewGauge(clusterProxyCAExpiryDesc, float64(notAfter.UTC().Unix()), c.bundle.clusterId, subject)
+	}
+	ch <- metrics.NewGauge(clusterProxyCAValidDesc, metrics.BoolToFloat64(c.bundle.valid), c.bundle.clusterId)
+	c.valid.Collect(ch, clusterProxyCAValidTransitionDesc, c.bundle.clusterId)
+	ch <- metrics.NewGauge(clusterProxyCAValidBlocksDesc, float64(c.bundle.validBlocks), c.bundle.clusterId)
+	for _, reason := range blockReasons {
+		ch <- metrics.NewGauge(clusterProxyCAInvalidBlocksDesc, float64(c.bundle.invalidBlocks[reason]), c.bundle.clusterId, reason)
+	}
+	chainIssues := map[string]int{}
+	for _, issue := range c.bundle.chainIssues {
+		chainIssues[issue.reason]++
+		ch <- metrics.NewGauge(clusterProxyCACertificateChainIssueDesc, 1,
+			c.bundle.clusterId, issue.fingerprint, issue.subject, issue.reason)
+	}
+	for _, reason := range chainIssueReasons {
+		ch <- metrics.NewGauge(clusterProxyCAChainIssuesDesc, float64(chainIssues[reason]), c.bundle.clusterId, reason)
+	}
+
+	// Expiry depends on the wall clock, so it is evaluated at scrape time
+	now := time.Now()
+	flagged := map[string]int{reasonExpired: 0, reasonNotYetValid: 0, reasonWeakSignature: 0}
+	for _, cert := range c.bundle.certificates {
+		ch <- metrics.NewGauge(clusterProxyCACertificateNotBeforeDesc, float64(cert.notBefore.UTC().Unix()),
+			c.bundle.clusterId, cert.fingerprint, cert.subject)
+		ch <- metrics.NewGauge(clusterProxyCACertificateDaysUntilExpiryDesc, cert.notAfter.Sub(now).Hours()/24,
+			c.bundle.clusterId, cert.fingerprint, cert.subject)
+		ch <- metrics.NewGauge(clusterProxyCACertificateInfoDesc, 1,
+			c.bundle.clusterId, cert.fingerprint, cert.subject, cert.keyAlgorithm, strconv.Itoa(cert.keySize),
+			cert.signatureAlgorithm, strconv.FormatBool(cert.isCA), strconv.FormatBool(cert.selfSigned))
+		if now.After(cert.notAfter) {
+			flagged[reasonExpired]++
+		}
+		if now.Before(cert.notBefore) {
+			flagged[reasonNotYetValid]++
+		}
+		if cert.weakSignature {
+			flagged[reasonWeakSignature]++
+		}
+	}
+	for reason, count := range flagged {
+		ch <- metrics.NewGauge(clusterProxyCAFlaggedCertificatesDesc, float64(count), c.bundle.clusterId, reason)
+	}
+}
+
+// SetClusterProxyCA replaces the reported CA bundle. Certificates which are no longer
+// part of the bundle stop being reported. The certificates of a bundle holding invalid
+// blocks or certificates outside of their validity window are reported as well, while the
+// bundle is reported as invalid. The validity window is evaluated on every call, which the
+// resync of the bundle triggers after every aggregation loop. Chain issues are reported
+// without affecting the validity of the bundle.
+func (c *Collector) SetClusterProxyCA(uuid string, bundle *Bundle) {
+	now := time.Now()
+	expiries := make(map[string]time.Time, len(bundle.Certificates))
+	details := make(map[string]certificate, len(bundle.Certificates))
+	for _, cert := range bundle.Certificates {
+		expiries[cert.Subject.String()] = cert.NotAfter
+		d := newCertificate(cert)
+		details[d.fingerprint] = d
+	}
+	invalidBlocks := map[string]int{}
+	for _, block := range bundle.InvalidBlocks {
+		invalidBlocks[block.Reason]++
+	}
+	valid := bundle.Valid(now)
+	chainIssues := bundle.chainIssues()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.bundle = &caBundle{
+		clusterId:     uuid,
+		valid:         valid,
+		expiries:      expiries,
+		certificates:  details,
+		validBlocks:   bundle.ValidBlocks,
+		invalidBlocks: invalidBlocks,
+		chainIssues:   chainIssues,
+		lastConfirmed: now,
+	}
+	c.valid.Set(valid, now)
+}
+
+// DeleteClusterProxyCA stops reporting on the CA bundle
+func (c *Collector) DeleteClusterProxyCA() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.bundle = nil
+	c.valid.Reset()
+}
+
+// Sweep stops reporting on the CA bundle if it has not been confirmed since expiredBefore
+func (c *Collector) Sweep(expiredBefore time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.bundle != nil && c.bundle.lastConfirmed.Before(expiredBefore) {
+		log.Info("Dropping stale CA bundle metrics", "lastConfirmed", c.bundle.lastConfirmed)
+		c.bundle = nil
+		c.valid.Reset()
+	}
+}
+
+// Reset forgets everything reported on the CA bundle
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.bundle = nil
+	c.valid.Reset()
+}
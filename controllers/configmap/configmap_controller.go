@@ -16,13 +16,12 @@ package configmap
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/openshift/cluster-network-operator/pkg/names"
-	"github.com/openshift/cluster-network-operator/pkg/util/validation"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,9 +41,9 @@ var log = logf.Log.WithName("controller_configmap")
 // ConfigMapReconciler reconciles a ConfigMap object
 type ConfigMapReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
 }
 
 // Reconcile reads that state of the cluster for a ConfigMap object and makes changes based the contained data
@@ -52,11 +51,6 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	reqLogger.Info("Reconciling ConfigMap")
 
-	// Initially reset the metric - old certificates might have been removed
-	// (that were invalid), and not be part of the bundle anymore at all.
-	r.MetricsAggregator.ResetClusterProxyCAExpiry()
-	r.MetricsAggregator.ResetClusterProxyCAValid()
-
 	// Fetch the ConfigMap openshift-config/user-ca-bundle
 	cfgMap := &corev1.ConfigMap{}
 	ns := names.ADDL_TRUST_BUNDLE_CONFIGMAP_NS
@@ -66,36 +60,39 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			r.Metrics.DeleteClusterProxyCA()
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
 
-	certBundle, _, err := validation.TrustBundleConfigMap(cfgMap, names.TRUSTED_CA_BUNDLE_CONFIGMAP_KEY)
-	if err != nil {
-		// Reporting of failed validation is already funneled through CVO via ClusterOperatorDegraded. This is to explicitly catch this condition
-		// and handle gracefully rather then causing stacktrace.
-		if strings.Contains(err.Error(), "failed parsing certificate") {
-			reqLogger.Info("failed parsing certificate")
-			r.MetricsAggregator.SetClusterProxyCAValid(r.ClusterId, false)
-			reqLogger.Info("setting CA valid metric to false")
-			return ctrl.Result{}, nil
-		}
-		return ctrl.Result{}, err
+	data, ok := cfgMap.Data[names.TRUSTED_CA_BUNDLE_CONFIGMAP_KEY]
+	if !ok || len(data) == 0 {
+		r.Metrics.DeleteClusterProxyCA()
+		return ctrl.Result{}, fmt.Errorf("data key %q is missing or empty in ConfigMap %q", names.TRUSTED_CA_BUNDLE_CONFIGMAP_KEY, cfgMap.Name)
 	}
 
-	countCertBundle := len(certBundle)
-	if countCertBundle == 0 {
+	// Reporting of failed validation is already funneled through CVO via ClusterOperatorDegraded.
+	// Every block is parsed on its own, so that the valid certificates keep being reported.
+	bundle := ParseBundle([]byte(data))
+	for _, block := range bundle.InvalidBlocks {
+		reqLogger.Info("Invalid PEM block in user-ca-bundle", "block", block.Index, "reason", block.Reason, "error", block.Err.Error())
+	}
+	if len(bundle.Certificates) == 0 && len(bundle.InvalidBlocks) == 0 {
 		reqLogger.Info("No cert bundles found in user-ca-bundle")
+		r.Metrics.DeleteClusterProxyCA()
 		return ctrl.Result{}, nil
 	}
-	reqLogger.Info(fmt.Sprintf("Found %d cert bundles", countCertBundle))
-	for _, cert := range certBundle {
+	reqLogger.Info(fmt.Sprintf("Found %d cert bundles", len(bundle.Certificates)))
+	for _, cert := range bundle.Certificates {
 		reqLogger.Info(fmt.Sprintf("Certificate Expiry %d", cert.NotAfter.Unix()))
-		r.MetricsAggregator.SetClusterProxyCAExpiry(r.ClusterId, cert.Subject.String(), cert.NotAfter.UTC().Unix())
-		r.MetricsAggregator.SetClusterProxyCAValid(r.ClusterId, true)
 	}
+	for _, issue := range bundle.chainIssues() {
+		reqLogger.Info(chainIssueMessages[issue.reason], "subject", issue.subject, "issuer", issue.issuer,
+			"fingerprint", issue.fingerprint, "copies", issue.copies)
+	}
+	r.Metrics.SetClusterProxyCA(r.ClusterId, bundle)
 	return ctrl.Result{}, nil
 }
 
@@ -118,5 +115,8 @@ func (r *ConfigMapReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return evt.Object.GetName() == userCABundleConfigMapName && evt.Object.GetNamespace() == names.ADDL_TRUST_BUNDLE_CONFIGMAP_NS
 			},
 		}).
-		Complete(r)
+		WatchesRawSource(r.Metrics.ResyncSource(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: names.ADDL_TRUST_BUNDLE_CONFIGMAP_NS, Name: userCABundleConfigMapName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
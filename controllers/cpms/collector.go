@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpms
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName         = "cpms"
+	cpmsMetric            = "cpms_enabled"
+	cpmsInstanceTypeLabel = "label_node_kubernetes_io_instance_type"
+)
+
+var (
+	cpmsDesc = metrics.NewDesc(
+		cpmsMetric,
+		"Indicates if the controlplanemachineset is enabled",
+		metrics.ClusterIDLabel, cpmsInstanceTypeLabel,
+	)
+	cpmsTransitionDesc = metrics.NewTransitionDesc(cpmsMetric, metrics.ClusterIDLabel)
+)
+
+type controlPlaneMachineSet struct {
+	clusterId    string
+	instanceType string
+	enabled      bool
+}
+
+// Collector exposes the state of the ControlPlaneMachineSet
+type Collector struct {
+	metrics.ReconcileStatus
+
+	// cpms is nil until the ControlPlaneMachineSet has been read
+	cpms *controlPlaneMachineSet
+	// enabled tracks the transitions of cpms.enabled, regardless of the instance type
+	enabled metrics.BoolTransition
+	mutex   sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cpmsDesc
+	ch <- cpmsTransitionDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.cpms == nil {
+		return
+	}
+	ch <- metrics.NewGauge(cpmsDesc, metrics.BoolToFloat64(c.cpms.enabled), c.cpms.clusterId, c.cpms.instanceType)
+	c.enabled.Collect(ch, cpmsTransitionDesc, c.cpms.clusterId)
+}
+
+func (c *Collector) SetCPMSEnabled(uuid string, instance_type string, enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cpms = &controlPlaneMachineSet{
+		clusterId:    uuid,
+		instanceType: instance_type,
+		enabled:      enabled,
+	}
+	c.enabled.Set(enabled, time.Now())
+}
+
+// Reset forgets everything reported on the ControlPlaneMachineSet
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cpms = nil
+	c.enabled.Reset()
+}
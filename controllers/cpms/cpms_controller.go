@@ -23,6 +23,7 @@ import (
 	"github.com/openshift/osd-metrics-exporter/controllers/utils"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,9 +39,9 @@ const (
 // CPMSReconciler reconciles the cpms object
 type CPMSReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
 }
 
 func (r *CPMSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -102,9 +103,9 @@ func (r *CPMSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 
 	if cpms.Spec.State == "Active" {
-		r.MetricsAggregator.SetCPMSEnabled(r.ClusterId, instance_type, true)
+		r.Metrics.SetCPMSEnabled(r.ClusterId, instance_type, true)
 	} else {
-		r.MetricsAggregator.SetCPMSEnabled(r.ClusterId, instance_type, false)
+		r.Metrics.SetCPMSEnabled(r.ClusterId, instance_type, false)
 	}
 	return utils.DoNotRequeue()
 }
@@ -112,5 +113,8 @@ func (r *CPMSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 func (r *CPMSReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&machinev1.ControlPlaneMachineSet{}).
-		Complete(r)
+		WatchesRawSource(r.Metrics.ResyncSource(&machinev1.ControlPlaneMachineSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cpmsNamespace, Name: cpmsName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
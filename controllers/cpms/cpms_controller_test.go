@@ -18,14 +18,12 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -164,9 +162,7 @@ cpms_enabled{_id="cluster-id",label_node_kubernetes_io_instance_type="custom-4-1
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, "cluster-id")
-			done := metricsAggregator.Run()
-			defer close(done)
+			collector := NewCollector()
 			err := machinev1.Install(scheme.Scheme)
 			require.NoError(t, err)
 
@@ -175,9 +171,9 @@ cpms_enabled{_id="cluster-id",label_node_kubernetes_io_instance_type="custom-4-1
 
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(makeTestCPMS(testName, testNamespace, tc.cpmsSpec)).Build()
 			reconciler := CPMSReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         "cluster-id",
+				Client:    fakeClient,
+				Metrics:   collector,
+				ClusterId: "cluster-id",
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -191,16 +187,13 @@ cpms_enabled{_id="cluster-id",label_node_kubernetes_io_instance_type="custom-4-1
 			}
 			require.NoError(t, err)
 
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testCPMS machinev1.ControlPlaneMachineSet
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: testName, Namespace: testNamespace}, &testCPMS)
 			require.NoError(t, err)
 
-			metric := metricsAggregator.GetCPMSMetric()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedCPMSResults))
+			err = testutil.CollectAndCompare(collector, strings.NewReader(tc.expectedCPMSResults), "cpms_enabled")
 			require.NoError(t, err)
 		})
 	}
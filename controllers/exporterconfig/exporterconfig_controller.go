@@ -0,0 +1,183 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporterconfig
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
+	"github.com/openshift/osd-metrics-exporter/controllers/utils"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var log = logf.Log.WithName("controller_exporterconfig")
+
+// Configurable is implemented by reconcilers whose settings can be changed at runtime
+type Configurable interface {
+	ApplyConfig(spec *v1alpha1.MetricsExporterConfigSpec)
+}
+
+// ExporterConfigReconciler applies the MetricsExporterConfig at runtime and reports the state of the
+// collectors in its status
+type ExporterConfigReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Registry *metrics.Registry
+	// Configurables are the reconcilers receiving the settings of every applied configuration
+	Configurables []Configurable
+	// DefaultStaleSeriesTTL is the stale series TTL used unless the configuration sets one
+	DefaultStaleSeriesTTL time.Duration
+
+	// observedGeneration is the generation of the last applied MetricsExporterConfig
+	observedGeneration int64
+}
+
+// Reconcile applies the MetricsExporterConfig and updates its status. Errors of the collectors do not
+// trigger any event, so the status is refreshed periodically. Without a MetricsExporterConfig every
+// setting falls back to its default.
+func (r *ExporterConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Name", req.Name)
+	reqLogger.Info("Reconciling MetricsExporterConfig")
+
+	config := &v1alpha1.MetricsExporterConfig{}
+	err := r.Get(ctx, client.ObjectKey{Name: v1alpha1.MetricsExporterConfigName}, config)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.Apply(&v1alpha1.MetricsExporterConfig{}); err != nil {
+				return utils.RequeueWithError(err)
+			}
+			return utils.DoNotRequeue()
+		}
+		return utils.RequeueWithError(err)
+	}
+	if err := r.Apply(config); err != nil {
+		return utils.RequeueWithError(err)
+	}
+
+	status := v1alpha1.MetricsExporterConfigStatus{
+		ObservedGeneration: r.observedGeneration,
+		Collectors:         collectorStatus(config, r.Registry.Status()),
+	}
+	if !equality.Semantic.DeepEqual(config.Status, status) {
+		reqLogger.Info("Updating MetricsExporterConfig status")
+		config.Status = status
+		if err := r.Status().Update(ctx, config); err != nil {
+			return utils.RequeueWithError(err)
+		}
+	}
+	return utils.RequeueAfter(metrics.AggregatorResyncInterval)
+}
+
+// Apply applies every setting of config which does not require a restart: the aggregation interval,
+// the stale series TTL, which collectors are enabled and the settings of the Configurables.
+// Applying the same configuration again does not change anything.
+func (r *ExporterConfigReconciler) Apply(config *v1alpha1.MetricsExporterConfig) error {
+	if config.Generation != r.observedGeneration {
+		log.Info("Applying MetricsExporterConfig", "generation", config.Generation)
+	}
+	spec := &config.Spec
+
+	aggregationInterval := metrics.AggregatorResyncInterval
+	if spec.AggregationInterval != nil && spec.AggregationInterval.Duration > 0 {
+		aggregationInterval = spec.AggregationInterval.Duration
+	}
+	r.Registry.SetAggregationInterval(aggregationInterval)
+	staleSeriesTTL := r.DefaultStaleSeriesTTL
+	if spec.StaleSeriesTTL != nil {
+		staleSeriesTTL = spec.StaleSeriesTTL.Duration
+	}
+	r.Registry.SetStaleSeriesTTL(staleSeriesTTL)
+
+	var errs []error
+	for _, c := range r.Registry.Status() {
+		enabled := spec.IsCollectorEnabled(c.Name)
+		if enabled != c.Active {
+			log.Info("Changing collector state", "collector", c.Name, "enabled", enabled)
+		}
+		if err := r.Registry.SetEnabled(c.Name, enabled); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, c := range r.Configurables {
+		c.ApplyConfig(spec)
+	}
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	r.observedGeneration = config.Generation
+	return nil
+}
+
+// collectorStatus reports the state of the registered collectors, and the collectors disabled in the config
+// which are not registered, e.g. because their CRD is not installed, as inactive
+func collectorStatus(config *v1alpha1.MetricsExporterConfig, registered []metrics.CollectorStatus) []v1alpha1.CollectorStatus {
+	var status []v1alpha1.CollectorStatus
+	isRegistered := map[string]bool{}
+	for _, c := range registered {
+		isRegistered[c.Name] = true
+		collector := v1alpha1.CollectorStatus{
+			Name:      c.Name,
+			Active:    c.Active,
+			LastError: c.LastError,
+		}
+		if !c.LastErrorTime.IsZero() {
+			collector.LastErrorTime = &metav1.Time{Time: c.LastErrorTime}
+		}
+		status = append(status, collector)
+	}
+	for _, c := range config.Spec.Collectors {
+		if !c.Enabled && !isRegistered[c.Name] {
+			status = append(status, v1alpha1.CollectorStatus{Name: c.Name})
+		}
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Name < status[j].Name
+	})
+	return status
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ExporterConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.MetricsExporterConfig{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(evt event.CreateEvent) bool {
+				return evt.Object.GetName() == v1alpha1.MetricsExporterConfigName
+			},
+			DeleteFunc: func(evt event.DeleteEvent) bool {
+				return evt.Object.GetName() == v1alpha1.MetricsExporterConfigName
+			},
+			UpdateFunc: func(evt event.UpdateEvent) bool {
+				// Status updates do not change the generation
+				return evt.ObjectNew.GetName() == v1alpha1.MetricsExporterConfigName &&
+					evt.ObjectNew.GetGeneration() != evt.ObjectOld.GetGeneration()
+			},
+			GenericFunc: func(evt event.GenericEvent) bool {
+				return evt.Object.GetName() == v1alpha1.MetricsExporterConfigName
+			},
+		}).
+		Complete(r)
+}
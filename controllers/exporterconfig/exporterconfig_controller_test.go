@@ -0,0 +1,161 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporterconfig
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type testCollector struct {
+	metrics.ReconcileStatus
+	name  string
+	reset bool
+}
+
+func (c *testCollector) Name() string                        { return c.name }
+func (c *testCollector) Describe(ch chan<- *prometheus.Desc) {}
+func (c *testCollector) Collect(ch chan<- prometheus.Metric) {}
+func (c *testCollector) Reset()                              { c.reset = true }
+
+type testConfigurable struct {
+	spec *v1alpha1.MetricsExporterConfigSpec
+}
+
+func (c *testConfigurable) ApplyConfig(spec *v1alpha1.MetricsExporterConfigSpec) { c.spec = spec }
+
+func reconcileExporterConfig(t *testing.T, reconciler *ExporterConfigReconciler) ctrl.Result {
+	result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: v1alpha1.MetricsExporterConfigName},
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestReconcileExporterConfig_Reconcile(t *testing.T) {
+	require.NoError(t, v1alpha1.AddToScheme(scheme.Scheme))
+
+	failing := &testCollector{name: "failing"}
+	failing.RecordError(fmt.Errorf("failed to get object"))
+	registry := metrics.NewRegistry(time.Minute)
+	registry.MustRegister(&testCollector{name: "healthy"}, failing)
+
+	config := &v1alpha1.MetricsExporterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.MetricsExporterConfigName, Generation: 2},
+		Spec: v1alpha1.MetricsExporterConfigSpec{
+			Collectors: []v1alpha1.CollectorConfig{
+				{Name: "disabled", Enabled: false},
+				{Name: "healthy", Enabled: true},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(config).WithStatusSubresource(config).Build()
+	reconciler := &ExporterConfigReconciler{
+		Client:   fakeClient,
+		Registry: registry,
+	}
+	result := reconcileExporterConfig(t, reconciler)
+	require.Equal(t, metrics.AggregatorResyncInterval, result.RequeueAfter)
+
+	updated := &v1alpha1.MetricsExporterConfig{}
+	require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: v1alpha1.MetricsExporterConfigName}, updated))
+	require.EqualValues(t, 2, updated.Status.ObservedGeneration)
+	require.Len(t, updated.Status.Collectors, 3)
+
+	disabled, failed, healthy := updated.Status.Collectors[0], updated.Status.Collectors[1], updated.Status.Collectors[2]
+	require.Equal(t, v1alpha1.CollectorStatus{Name: "disabled"}, disabled)
+	require.Equal(t, "failing", failed.Name)
+	require.True(t, failed.Active)
+	require.Equal(t, "failed to get object", failed.LastError)
+	require.NotNil(t, failed.LastErrorTime)
+	require.Equal(t, v1alpha1.CollectorStatus{Name: "healthy", Active: true}, healthy)
+}
+
+func TestReconcileExporterConfigNotFound_Reconcile(t *testing.T) {
+	require.NoError(t, v1alpha1.AddToScheme(scheme.Scheme))
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	reconciler := &ExporterConfigReconciler{
+		Client:   fakeClient,
+		Registry: metrics.NewRegistry(time.Minute),
+	}
+	result := reconcileExporterConfig(t, reconciler)
+	require.Zero(t, result.RequeueAfter)
+}
+
+func TestReconcileExporterConfigChanges_Reconcile(t *testing.T) {
+	require.NoError(t, v1alpha1.AddToScheme(scheme.Scheme))
+
+	collector := &testCollector{name: "collector"}
+	registry := metrics.NewRegistry(time.Minute)
+	registry.MustRegister(collector)
+	configurable := &testConfigurable{}
+
+	config := &v1alpha1.MetricsExporterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.MetricsExporterConfigName},
+		Spec: v1alpha1.MetricsExporterConfigSpec{
+			Collectors: []v1alpha1.CollectorConfig{{Name: "collector", Enabled: false}},
+			PullSecret: &v1alpha1.PullSecretConfig{ExpectedRegistries: []string{"mirror.example.com"}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(config).WithStatusSubresource(config).Build()
+	reconciler := &ExporterConfigReconciler{
+		Client:        fakeClient,
+		Registry:      registry,
+		Configurables: []Configurable{configurable},
+	}
+
+	// Disabling a collector stops its reconciler and drops its state
+	reconcileExporterConfig(t, reconciler)
+	require.False(t, collector.Enabled())
+	require.True(t, collector.reset)
+	require.False(t, registry.Status()[0].Active)
+	require.Equal(t, []string{"mirror.example.com"}, configurable.spec.PullSecret.ExpectedRegistries)
+
+	updated := &v1alpha1.MetricsExporterConfig{}
+	require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: v1alpha1.MetricsExporterConfigName}, updated))
+	require.Equal(t, []v1alpha1.CollectorStatus{{Name: "collector"}}, updated.Status.Collectors)
+
+	// Enabling it again does not need a restart
+	updated.Spec.Collectors[0].Enabled = true
+	require.NoError(t, fakeClient.Update(context.TODO(), updated))
+	reconcileExporterConfig(t, reconciler)
+	require.True(t, collector.Enabled())
+	require.True(t, registry.Status()[0].Active)
+
+	// Deleting the configuration restores the defaults
+	require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: v1alpha1.MetricsExporterConfigName}, updated))
+	updated.Spec.Collectors[0].Enabled = false
+	require.NoError(t, fakeClient.Update(context.TODO(), updated))
+	reconcileExporterConfig(t, reconciler)
+	require.False(t, collector.Enabled())
+	require.NoError(t, fakeClient.Delete(context.TODO(), updated))
+	reconcileExporterConfig(t, reconciler)
+	require.True(t, collector.Enabled())
+	require.Nil(t, configurable.spec.PullSecret)
+}
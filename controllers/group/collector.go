@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const collectorName = "group"
+
+const clusterAdminMetric = "cluster_admin_enabled"
+
+var (
+	clusterAdminDesc = metrics.NewDesc(
+		clusterAdminMetric,
+		"Indicates if the cluster-admin role is enabled",
+		metrics.ClusterIDLabel,
+	)
+	clusterAdminTransitionDesc = metrics.NewTransitionDesc(clusterAdminMetric, metrics.ClusterIDLabel)
+)
+
+// Collector exposes whether the cluster-admins group has any members
+type Collector struct {
+	metrics.ReconcileStatus
+
+	clusterId    string
+	clusterAdmin metrics.BoolTransition
+	mutex        sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector(clusterId string) *Collector {
+	return &Collector{
+		clusterId: clusterId,
+	}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterAdminDesc
+	ch <- clusterAdminTransitionDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ch <- metrics.NewGauge(clusterAdminDesc, metrics.BoolToFloat64(c.clusterAdmin.Value()), c.clusterId)
+	c.clusterAdmin.Collect(ch, clusterAdminTransitionDesc, c.clusterId)
+}
+
+func (c *Collector) SetClusterAdmin(uuid string, enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterId = uuid
+	c.clusterAdmin.Set(enabled, time.Now())
+}
+
+// Reset forgets everything reported on the cluster admin group
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterAdmin.Reset()
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/openshift/osd-metrics-exporter/controllers/utils"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,9 +40,9 @@ var log = logf.Log.WithName("controller_group")
 // GroupReconciler reconciles a Group object
 type GroupReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
 }
 
 // Reconcile reads that state of the cluster for a Group object and makes changes based on the state read
@@ -70,9 +71,9 @@ func (r *GroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				return ctrl.Result{}, err
 			}
 		}
-		r.MetricsAggregator.SetClusterAdmin(r.ClusterId, len(group.Users) > 0)
+		r.Metrics.SetClusterAdmin(r.ClusterId, len(group.Users) > 0)
 	} else {
-		r.MetricsAggregator.SetClusterAdmin(r.ClusterId, false)
+		r.Metrics.SetClusterAdmin(r.ClusterId, false)
 		if utils.ContainsString(group.Finalizers, finalizer) {
 			controllerutil.RemoveFinalizer(group, finalizer)
 			if err := r.Update(ctx, group); err != nil {
@@ -101,5 +102,8 @@ func (r *GroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return evt.Object.GetName() == clusterAdminGroupName
 			},
 		}).
-		Complete(r)
+		WatchesRawSource(r.Metrics.ResyncSource(&userv1.Group{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterAdminGroupName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
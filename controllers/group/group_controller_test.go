@@ -2,11 +2,11 @@ package group
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
-	"time"
 
 	userv1 "github.com/openshift/api/user/v1"
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -55,8 +55,8 @@ func TestReconcileGroup_Reconcile(t *testing.T) {
 			}
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(group).Build()
 			reconcileGroup := &GroupReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metrics.NewMetricsAggregator(time.Second*10, tc.clusterId),
+				Client:  fakeClient,
+				Metrics: NewCollector(tc.clusterId),
 			}
 			_, err = reconcileGroup.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{Name: clusterAdminGroupName},
@@ -69,9 +69,14 @@ func TestReconcileGroup_Reconcile(t *testing.T) {
 			} else {
 				require.Contains(t, group.Finalizers, finalizer)
 			}
-			metric := reconcileGroup.MetricsAggregator.GetClusterRoleMetric()
-			value := testutil.ToFloat64(metric)
-			require.EqualValues(t, tc.result, value)
+			expected := fmt.Sprintf(`
+# HELP cluster_admin_enabled Indicates if the cluster-admin role is enabled
+# TYPE cluster_admin_enabled gauge
+cluster_admin_enabled{_id=%q,name="osd_exporter"} %d
+`, tc.clusterId, tc.result)
+			err = testutil.CollectAndCompare(reconcileGroup.Metrics, strings.NewReader(expected), "cluster_admin_enabled")
+			require.NoError(t, err)
+			require.Equal(t, 1, testutil.CollectAndCount(reconcileGroup.Metrics, "cluster_admin_enabled_last_transition_timestamp_seconds"))
 		})
 	}
 }
@@ -0,0 +1,121 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagemirror
+
+import (
+	"sync"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName = "imagemirror"
+
+	kindLabel   = "kind"
+	sourceLabel = "source"
+	mirrorLabel = "mirror"
+)
+
+var (
+	imageMirrorSetsDesc = metrics.NewDesc(
+		"image_mirror_sets",
+		"Number of mirror sets of a kind served by the cluster",
+		metrics.ClusterIDLabel, kindLabel,
+	)
+	imageMirrorSourceDesc = metrics.NewDesc(
+		"image_mirror_source",
+		"Source repository mirrored by the mirror sets of a kind",
+		metrics.ClusterIDLabel, kindLabel, sourceLabel,
+	)
+	imageMirrorCredentialDesc = metrics.NewDesc(
+		"image_mirror_credential",
+		"Indicates if the cluster pull secret holds a credential for a mirror of the mirror sets",
+		metrics.ClusterIDLabel, mirrorLabel,
+	)
+)
+
+// Mirrors is what is reported on the mirror sets of the cluster
+type Mirrors struct {
+	// Sets is the number of mirror sets by kind, for every kind served by the cluster
+	Sets map[Kind]int
+	// Sources are the mirrored source repositories by kind
+	Sources map[Kind][]string
+	// Credentials is whether the pull secret holds a credential by mirror
+	Credentials map[string]bool
+}
+
+// Collector exposes the mirror sets of the cluster
+type Collector struct {
+	metrics.ReconcileStatus
+
+	clusterId string
+	// mirrors is nil until the mirror sets have been listed
+	mirrors *Mirrors
+	mutex   sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- imageMirrorSetsDesc
+	ch <- imageMirrorSourceDesc
+	ch <- imageMirrorCredentialDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.mirrors == nil {
+		return
+	}
+	for kind, sets := range c.mirrors.Sets {
+		ch <- metrics.NewGauge(imageMirrorSetsDesc, float64(sets), c.clusterId, string(kind))
+	}
+	for kind, sources := range c.mirrors.Sources {
+		for _, source := range sources {
+			ch <- metrics.NewGauge(imageMirrorSourceDesc, 1, c.clusterId, string(kind), source)
+		}
+	}
+	for mirror, credential := range c.mirrors.Credentials {
+		ch <- metrics.NewGauge(imageMirrorCredentialDesc, metrics.BoolToFloat64(credential), c.clusterId, mirror)
+	}
+}
+
+// SetMirrors replaces what is reported on the mirror sets. The maps of mirrors are kept and must not
+// be modified afterwards.
+func (c *Collector) SetMirrors(uuid string, mirrors Mirrors) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterId = uuid
+	c.mirrors = &mirrors
+}
+
+// Reset forgets the mirror sets
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.mirrors = nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagemirror
+
+import (
+	"context"
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"github.com/openshift/osd-metrics-exporter/controllers/pullsecret"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Kind is a kind of mirror set
+type Kind string
+
+const (
+	KindImageContentSourcePolicy Kind = "ImageContentSourcePolicy"
+	KindImageDigestMirrorSet     Kind = "ImageDigestMirrorSet"
+	KindImageTagMirrorSet        Kind = "ImageTagMirrorSet"
+)
+
+// Kinds are the kinds of mirror sets, not every cluster serves all of them
+var Kinds = []Kind{KindImageContentSourcePolicy, KindImageDigestMirrorSet, KindImageTagMirrorSet}
+
+var log = logf.Log.WithName("controller_imagemirror")
+
+// Resource returns the API resource of the kind
+func (k Kind) Resource() schema.GroupVersionResource {
+	switch k {
+	case KindImageContentSourcePolicy:
+		return operatorv1alpha1.GroupVersion.WithResource("imagecontentsourcepolicies")
+	case KindImageDigestMirrorSet:
+		return configv1.GroupVersion.WithResource("imagedigestmirrorsets")
+	default:
+		return configv1.GroupVersion.WithResource("imagetagmirrorsets")
+	}
+}
+
+func (k Kind) newObject() client.Object {
+	switch k {
+	case KindImageContentSourcePolicy:
+		return &operatorv1alpha1.ImageContentSourcePolicy{}
+	case KindImageDigestMirrorSet:
+		return &configv1.ImageDigestMirrorSet{}
+	default:
+		return &configv1.ImageTagMirrorSet{}
+	}
+}
+
+func (k Kind) newList() client.ObjectList {
+	switch k {
+	case KindImageContentSourcePolicy:
+		return &operatorv1alpha1.ImageContentSourcePolicyList{}
+	case KindImageDigestMirrorSet:
+		return &configv1.ImageDigestMirrorSetList{}
+	default:
+		return &configv1.ImageTagMirrorSetList{}
+	}
+}
+
+// mirrorRule is a source repository and the repositories mirroring it
+type mirrorRule struct {
+	source  string
+	mirrors []string
+}
+
+// mirrorRules returns the number of mirror sets of list and their rules
+func mirrorRules(list client.ObjectList) (int, []mirrorRule) {
+	var rules []mirrorRule
+	switch list := list.(type) {
+	case *operatorv1alpha1.ImageContentSourcePolicyList:
+		for _, policy := range list.Items {
+			for _, m := range policy.Spec.RepositoryDigestMirrors {
+				rules = append(rules, mirrorRule{source: m.Source, mirrors: m.Mirrors})
+			}
+		}
+		return len(list.Items), rules
+	case *configv1.ImageDigestMirrorSetList:
+		for _, set := range list.Items {
+			for _, m := range set.Spec.ImageDigestMirrors {
+				rules = append(rules, mirrorRule{source: m.Source, mirrors: imageMirrors(m.Mirrors)})
+			}
+		}
+		return len(list.Items), rules
+	case *configv1.ImageTagMirrorSetList:
+		for _, set := range list.Items {
+			for _, m := range set.Spec.ImageTagMirrors {
+				rules = append(rules, mirrorRule{source: m.Source, mirrors: imageMirrors(m.Mirrors)})
+			}
+		}
+		return len(list.Items), rules
+	}
+	return 0, nil
+}
+
+func imageMirrors(mirrors []configv1.ImageMirror) []string {
+	converted := make([]string, len(mirrors))
+	for i, mirror := range mirrors {
+		converted[i] = string(mirror)
+	}
+	return converted
+}
+
+// ImageMirrorReconciler reports the mirror sets of the cluster and whether the cluster pull secret
+// holds a credential for their mirrors
+type ImageMirrorReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
+
+	// Kinds are the kinds of mirror sets served by the cluster, only those are watched
+	Kinds []Kind
+}
+
+// Reconcile lists the mirror sets of every kind. Every request takes the full inventory, as mirrors
+// are shared between the mirror sets.
+func (r *ImageMirrorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling image mirror sets")
+
+	registries, err := pullsecret.ReadRegistries(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	mirrors := Mirrors{
+		Sets:        map[Kind]int{},
+		Sources:     map[Kind][]string{},
+		Credentials: map[string]bool{},
+	}
+	for _, kind := range r.Kinds {
+		list := kind.newList()
+		if err := r.List(ctx, list); err != nil {
+			return ctrl.Result{}, err
+		}
+		sets, rules := mirrorRules(list)
+		mirrors.Sets[kind] = sets
+		sources := map[string]bool{}
+		for _, rule := range rules {
+			sources[rule.source] = true
+			for _, mirror := range rule.mirrors {
+				_, mirrors.Credentials[mirror] = pullsecret.CredentialFor(registries, mirror)
+			}
+		}
+		for source := range sources {
+			mirrors.Sources[kind] = append(mirrors.Sources[kind], source)
+		}
+		sort.Strings(mirrors.Sources[kind])
+	}
+
+	var missing []string
+	for mirror, credential := range mirrors.Credentials {
+		if !credential {
+			missing = append(missing, mirror)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		reqLogger.Info("Pull secret holds no credential for mirrors", "mirrors", missing)
+	}
+	r.Metrics.SetMirrors(r.ClusterId, mirrors)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ImageMirrorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Every object is mapped to the request of the pull secret, which is the one of the resyncs
+	inventory := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: pullsecret.PullSecretKey}}
+	})
+	isPullSecret := builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == pullsecret.PullSecretKey.Namespace && obj.GetName() == pullsecret.PullSecretKey.Name
+	}))
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(collectorName).
+		Watches(&corev1.Secret{}, inventory, isPullSecret).
+		WatchesRawSource(r.Metrics.ResyncSource(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pullsecret.PullSecretKey.Namespace, Name: pullsecret.PullSecretKey.Name},
+		}))
+	for _, kind := range r.Kinds {
+		b = b.Watches(kind.newObject(), inventory)
+	}
+	return b.Complete(metrics.NewReconciler(r.Metrics, r))
+}
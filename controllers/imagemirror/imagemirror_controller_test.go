@@ -0,0 +1,164 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagemirror
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"github.com/openshift/osd-metrics-exporter/controllers/pullsecret"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "cluster-id"
+
+func TestKind(t *testing.T) {
+	require.NoError(t, configv1.Install(scheme.Scheme))
+	require.NoError(t, operatorv1alpha1.Install(scheme.Scheme))
+	for _, kind := range Kinds {
+		t.Run(string(kind), func(t *testing.T) {
+			gvk, _, err := scheme.Scheme.ObjectKinds(kind.newObject())
+			require.NoError(t, err)
+			require.Equal(t, string(kind), gvk[0].Kind)
+			require.Equal(t, gvk[0].GroupVersion(), kind.Resource().GroupVersion())
+			sets, _ := mirrorRules(kind.newList())
+			require.Equal(t, 0, sets)
+		})
+	}
+}
+
+func TestReconcileImageMirror_Reconcile(t *testing.T) {
+	require.NoError(t, corev1.AddToScheme(scheme.Scheme))
+	require.NoError(t, configv1.Install(scheme.Scheme))
+	require.NoError(t, operatorv1alpha1.Install(scheme.Scheme))
+
+	pullSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: pullsecret.PullSecretKey.Name, Namespace: pullsecret.PullSecretKey.Namespace},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths": {"mirror.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`),
+		},
+	}
+	objects := []client.Object{
+		pullSecret,
+		&operatorv1alpha1.ImageContentSourcePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "release"},
+			Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
+				RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{{
+					Source:  "quay.io/openshift-release-dev/ocp-release",
+					Mirrors: []string{"mirror.example.com/ocp/release"},
+				}},
+			},
+		},
+		&configv1.ImageDigestMirrorSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "release"},
+			Spec: configv1.ImageDigestMirrorSetSpec{
+				ImageDigestMirrors: []configv1.ImageDigestMirrors{{
+					Source:  "quay.io/openshift-release-dev/ocp-release",
+					Mirrors: []configv1.ImageMirror{"mirror.example.com/ocp/release"},
+				}},
+			},
+		},
+		&configv1.ImageDigestMirrorSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "operators"},
+			Spec: configv1.ImageDigestMirrorSetSpec{
+				ImageDigestMirrors: []configv1.ImageDigestMirrors{
+					{
+						Source:  "registry.redhat.io/redhat",
+						Mirrors: []configv1.ImageMirror{"mirror.example.com/redhat", "backup.example.com/redhat"},
+					},
+					{
+						Source:  "quay.io/openshift-release-dev/ocp-release",
+						Mirrors: []configv1.ImageMirror{"mirror.example.com/ocp/release"},
+					},
+				},
+			},
+		},
+		// Not served by the cluster
+		&configv1.ImageTagMirrorSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "tags"},
+			Spec: configv1.ImageTagMirrorSetSpec{
+				ImageTagMirrors: []configv1.ImageTagMirrors{{
+					Source:  "docker.io/library",
+					Mirrors: []configv1.ImageMirror{"tags.example.com/library"},
+				}},
+			},
+		},
+	}
+	collector := NewCollector()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+	reconciler := ImageMirrorReconciler{
+		Client:    fakeClient,
+		Metrics:   collector,
+		ClusterId: testClusterId,
+		Kinds:     []Kind{KindImageContentSourcePolicy, KindImageDigestMirrorSet},
+	}
+	reconcile := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: pullsecret.PullSecretKey})
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	expected := `
+# HELP image_mirror_credential Indicates if the cluster pull secret holds a credential for a mirror of the mirror sets
+# TYPE image_mirror_credential gauge
+image_mirror_credential{_id="cluster-id",mirror="backup.example.com/redhat",name="osd_exporter"} 0
+image_mirror_credential{_id="cluster-id",mirror="mirror.example.com/ocp/release",name="osd_exporter"} 1
+image_mirror_credential{_id="cluster-id",mirror="mirror.example.com/redhat",name="osd_exporter"} 1
+# HELP image_mirror_sets Number of mirror sets of a kind served by the cluster
+# TYPE image_mirror_sets gauge
+image_mirror_sets{_id="cluster-id",kind="ImageContentSourcePolicy",name="osd_exporter"} 1
+image_mirror_sets{_id="cluster-id",kind="ImageDigestMirrorSet",name="osd_exporter"} 2
+# HELP image_mirror_source Source repository mirrored by the mirror sets of a kind
+# TYPE image_mirror_source gauge
+image_mirror_source{_id="cluster-id",kind="ImageContentSourcePolicy",name="osd_exporter",source="quay.io/openshift-release-dev/ocp-release"} 1
+image_mirror_source{_id="cluster-id",kind="ImageDigestMirrorSet",name="osd_exporter",source="quay.io/openshift-release-dev/ocp-release"} 1
+image_mirror_source{_id="cluster-id",kind="ImageDigestMirrorSet",name="osd_exporter",source="registry.redhat.io/redhat"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	// Without pull secret no mirror has a credential
+	require.NoError(t, fakeClient.Delete(context.TODO(), pullSecret))
+	reconcile()
+	expected = `
+# HELP image_mirror_credential Indicates if the cluster pull secret holds a credential for a mirror of the mirror sets
+# TYPE image_mirror_credential gauge
+image_mirror_credential{_id="cluster-id",mirror="backup.example.com/redhat",name="osd_exporter"} 0
+image_mirror_credential{_id="cluster-id",mirror="mirror.example.com/ocp/release",name="osd_exporter"} 0
+image_mirror_credential{_id="cluster-id",mirror="mirror.example.com/redhat",name="osd_exporter"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "image_mirror_credential"))
+
+	// Served kinds without mirror sets are reported
+	for _, obj := range objects[1:4] {
+		require.NoError(t, fakeClient.Delete(context.TODO(), obj))
+	}
+	reconcile()
+	expected = `
+# HELP image_mirror_sets Number of mirror sets of a kind served by the cluster
+# TYPE image_mirror_sets gauge
+image_mirror_sets{_id="cluster-id",kind="ImageContentSourcePolicy",name="osd_exporter"} 0
+image_mirror_sets{_id="cluster-id",kind="ImageDigestMirrorSet",name="osd_exporter"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
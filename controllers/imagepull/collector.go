@@ -0,0 +1,102 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepull
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName = "imagepull"
+
+	registryLabel     = "registry"
+	reasonLabel       = "reason"
+	inPullSecretLabel = "in_pull_secret"
+)
+
+var imagePullFailuresDesc = metrics.NewDesc(
+	"image_pull_failures",
+	"Number of pods failing to pull an image from a registry, by cause of the failure and presence of a credential for the image in the cluster pull secret",
+	metrics.ClusterIDLabel, registryLabel, reasonLabel, inPullSecretLabel,
+)
+
+// Failure identifies the pods failing to pull an image for the same cause from the same registry
+type Failure struct {
+	// Registry is the host of the image, e.g. "quay.io"
+	Registry string
+	// Reason is the cause of the failure, e.g. ReasonAuth
+	Reason string
+	// InPullSecret is true if the cluster pull secret holds a credential for the image
+	InPullSecret bool
+}
+
+// Collector exposes the pods failing to pull images, aggregated by registry
+type Collector struct {
+	metrics.ReconcileStatus
+
+	clusterId string
+	// failures is the number of failing pods, nil until the pods have been listed
+	failures map[Failure]int
+	mutex    sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- imagePullFailuresDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for failure, pods := range c.failures {
+		ch <- metrics.NewGauge(imagePullFailuresDesc, float64(pods),
+			c.clusterId, failure.Registry, failure.Reason, strconv.FormatBool(failure.InPullSecret))
+	}
+}
+
+// SetFailures replaces the number of pods failing to pull images. Failures which no pod suffers
+// from anymore stop being reported.
+func (c *Collector) SetFailures(uuid string, failures map[Failure]int) {
+	copied := make(map[Failure]int, len(failures))
+	for failure, pods := range failures {
+		copied[failure] = pods
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterId = uuid
+	c.failures = copied
+}
+
+// Reset forgets the pods failing to pull images
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.failures = nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepull
+
+import (
+	"strings"
+)
+
+const (
+	// Reason labels of the image_pull_failures metric
+	ReasonAuth     = "auth"
+	ReasonNotFound = "not_found"
+	ReasonNetwork  = "network"
+	ReasonUnknown  = "unknown"
+
+	// dockerHubRegistry is the registry of images without registry host
+	dockerHubRegistry = "docker.io"
+)
+
+// failureCauses are the fragments of the pull errors of the container runtimes identifying the cause
+// of the failure, in the order they are looked for. Registries may deny access to missing images, hence
+// authentication errors come first.
+var failureCauses = []struct {
+	reason    string
+	fragments []string
+}{
+	{
+		reason: ReasonAuth,
+		fragments: []string{
+			"unauthorized",
+			"authentication required",
+			"no basic auth credentials",
+			"invalid username/password",
+			"access denied",
+			"requested access to the resource is denied",
+			"denied:",
+			"forbidden",
+		},
+	},
+	{
+		reason: ReasonNotFound,
+		fragments: []string{
+			"manifest unknown",
+			"name unknown",
+			"not found",
+			"does not exist",
+		},
+	},
+	{
+		reason: ReasonNetwork,
+		fragments: []string{
+			"dial tcp",
+			"no such host",
+			"connection refused",
+			"connection reset",
+			"network is unreachable",
+			"i/o timeout",
+			"context deadline exceeded",
+			"tls:",
+			"x509:",
+		},
+	},
+}
+
+// classifyFailure returns the cause of the pull error message, ReasonUnknown if it is not recognized
+func classifyFailure(message string) string {
+	message = strings.ToLower(message)
+	for _, cause := range failureCauses {
+		for _, fragment := range cause.fragments {
+			if strings.Contains(message, fragment) {
+				return cause.reason
+			}
+		}
+	}
+	return ReasonUnknown
+}
+
+// parseImage returns the registry host and the repository of image, e.g. "quay.io" and
+// "quay.io/org/image" for "quay.io/org/image:tag". Images without registry host are on Docker Hub.
+func parseImage(image string) (string, string) {
+	repository, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	host, path, found := strings.Cut(repository, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+		return dockerHubRegistry, dockerHubRegistry + "/" + repository
+	}
+	if host == "index.docker.io" {
+		return dockerHubRegistry, dockerHubRegistry + "/" + path
+	}
+	return host, repository
+}
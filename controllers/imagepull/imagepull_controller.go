@@ -0,0 +1,243 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepull
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/pullsecret"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// Waiting reasons of the containers failing to pull their image
+	reasonErrImagePull     = "ErrImagePull"
+	reasonImagePullBackOff = "ImagePullBackOff"
+
+	// pullFailedEventReason is the reason of the events the kubelet records on pull failures, whose
+	// message starts with pullFailedEventPrefix followed by the quoted image
+	pullFailedEventReason = "Failed"
+	pullFailedEventPrefix = "Failed to pull image "
+
+	// involvedObjectUIDField indexes the events by the UID of the object they were recorded on
+	involvedObjectUIDField = "involvedObject.uid"
+)
+
+var log = logf.Log.WithName("controller_imagepull")
+
+// ImagePullReconciler reports the pods failing to pull images, aggregated by registry, next to the
+// presence of a credential for the images in the cluster pull secret
+type ImagePullReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
+}
+
+// Reconcile lists every pod failing to pull an image. Every request takes the full count, as the
+// failures are aggregated across pods.
+func (r *ImagePullReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.Info("Reconciling image pull failures")
+
+	registries, err := pullsecret.ReadRegistries(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods); err != nil {
+		return ctrl.Result{}, err
+	}
+	failures := map[Failure]int{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		podFailures := map[Failure]bool{}
+		for _, status := range failingContainers(pod) {
+			message := status.State.Waiting.Message
+			// The message of a back-off does not tell the cause, which is only recorded by the events
+			if status.State.Waiting.Reason == reasonImagePullBackOff {
+				message, err = r.lastPullError(ctx, pod, status.Image)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			registry, repository := parseImage(status.Image)
+			_, inPullSecret := pullsecret.CredentialFor(registries, repository)
+			failure := Failure{Registry: registry, Reason: classifyFailure(message), InPullSecret: inPullSecret}
+			// Every reconcile takes the full count, only log the failures on demand
+			reqLogger.V(1).Info("Container is failing to pull its image", "pod", pod.Namespace+"/"+pod.Name,
+				"container", status.Name, "image", status.Image, "reason", failure.Reason, "inPullSecret", inPullSecret)
+			podFailures[failure] = true
+		}
+		for failure := range podFailures {
+			failures[failure]++
+		}
+	}
+	r.Metrics.SetFailures(r.ClusterId, failures)
+	return ctrl.Result{}, nil
+}
+
+// lastPullError returns the message of the last event recorded for pod on a failure to pull image,
+// an empty string if there is none
+func (r *ImagePullReconciler) lastPullError(ctx context.Context, pod *corev1.Pod, image string) (string, error) {
+	events := &corev1.EventList{}
+	if err := r.List(ctx, events, client.InNamespace(pod.Namespace),
+		client.MatchingFields{involvedObjectUIDField: string(pod.UID)}); err != nil {
+		return "", err
+	}
+	var last *corev1.Event
+	for i := range events.Items {
+		event := &events.Items[i]
+		if !isPullFailedEvent(event) ||
+			!strings.HasPrefix(event.Message, pullFailedEventPrefix+`"`+image+`"`) {
+			continue
+		}
+		if last == nil || eventTime(last).Before(eventTime(event)) {
+			last = event
+		}
+	}
+	if last == nil {
+		return "", nil
+	}
+	return last.Message, nil
+}
+
+// failingContainers returns the statuses of the containers of pod failing to pull their image
+func failingContainers(pod *corev1.Pod) []corev1.ContainerStatus {
+	var failing []corev1.ContainerStatus
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.InitContainerStatuses,
+		pod.Status.ContainerStatuses,
+		pod.Status.EphemeralContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if waiting := status.State.Waiting; waiting != nil &&
+				(waiting.Reason == reasonErrImagePull || waiting.Reason == reasonImagePullBackOff) {
+				failing = append(failing, status)
+			}
+		}
+	}
+	return failing
+}
+
+// isPullFailedEvent returns whether event was recorded on a pod failing to pull an image
+func isPullFailedEvent(event *corev1.Event) bool {
+	return event.InvolvedObject.Kind == "Pod" && event.Reason == pullFailedEventReason &&
+		strings.HasPrefix(event.Message, pullFailedEventPrefix)
+}
+
+// indexInvolvedObjectUID is the indexer of involvedObjectUIDField
+func indexInvolvedObjectUID(obj client.Object) []string {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil
+	}
+	return []string{string(event.InvolvedObject.UID)}
+}
+
+// eventTime returns when event last occurred
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// TrimPod is the cache transform of pods, keeping only what the controller reads as the pending pods of
+// the whole cluster are cached
+func TrimPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	return &corev1.Pod{
+		TypeMeta: pod.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              pod.Name,
+			Namespace:         pod.Namespace,
+			UID:               pod.UID,
+			ResourceVersion:   pod.ResourceVersion,
+			DeletionTimestamp: pod.DeletionTimestamp,
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses:      pod.Status.InitContainerStatuses,
+			ContainerStatuses:          pod.Status.ContainerStatuses,
+			EphemeralContainerStatuses: pod.Status.EphemeralContainerStatuses,
+		},
+	}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ImagePullReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Event{}, involvedObjectUIDField, indexInvolvedObjectUID); err != nil {
+		return err
+	}
+
+	// Every object is mapped to the request of the pull secret, which is the one of the resyncs
+	aggregate := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: pullsecret.PullSecretKey}}
+	})
+	hasFailure := func(obj client.Object) bool {
+		pod, ok := obj.(*corev1.Pod)
+		return ok && len(failingContainers(pod)) > 0
+	}
+	// Pods are only of interest while and right after failing to pull an image
+	failingPods := builder.WithPredicates(predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return hasFailure(evt.Object)
+		},
+		DeleteFunc: func(evt event.DeleteEvent) bool {
+			return hasFailure(evt.Object)
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			return hasFailure(evt.ObjectOld) || hasFailure(evt.ObjectNew)
+		},
+		GenericFunc: func(evt event.GenericEvent) bool {
+			return hasFailure(evt.Object)
+		},
+	})
+	pullFailedEvents := builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		event, ok := obj.(*corev1.Event)
+		return ok && isPullFailedEvent(event)
+	}))
+	isPullSecret := builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == pullsecret.PullSecretKey.Namespace && obj.GetName() == pullsecret.PullSecretKey.Name
+	}))
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(collectorName).
+		Watches(&corev1.Pod{}, aggregate, failingPods).
+		Watches(&corev1.Event{}, aggregate, pullFailedEvents).
+		Watches(&corev1.Secret{}, aggregate, isPullSecret).
+		WatchesRawSource(r.Metrics.ResyncSource(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pullsecret.PullSecretKey.Namespace, Name: pullsecret.PullSecretKey.Name},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
+}
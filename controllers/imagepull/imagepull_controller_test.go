@@ -0,0 +1,225 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepull
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/controllers/pullsecret"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testClusterId = "cluster-id"
+
+func makeTestPod(name string, uid types.UID, statuses ...corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "workloads", UID: uid},
+		Status:     corev1.PodStatus{ContainerStatuses: statuses},
+	}
+}
+
+func makeTestStatus(name, image, reason, message string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:  name,
+		Image: image,
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: message}},
+	}
+}
+
+func makeTestEvent(name string, uid types.UID, message string, last time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "workloads"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "workloads", UID: uid},
+		Reason:         pullFailedEventReason,
+		Message:        message,
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	for _, tc := range []struct {
+		message  string
+		expected string
+	}{
+		{
+			message:  `rpc error: code = Unknown desc = reading manifest latest in quay.io/org/image: unauthorized: access to the requested resource is not authorized`,
+			expected: ReasonAuth,
+		},
+		{
+			message:  `Failed to pull image "registry.example.com/image": pinging container registry registry.example.com: authentication required`,
+			expected: ReasonAuth,
+		},
+		{
+			message:  `rpc error: code = Unknown desc = reading manifest v1 in quay.io/org/image: manifest unknown`,
+			expected: ReasonNotFound,
+		},
+		{
+			message:  `rpc error: code = Unknown desc = pinging container registry registry.example.com: Get "https://registry.example.com/v2/": dial tcp: lookup registry.example.com: no such host`,
+			expected: ReasonNetwork,
+		},
+		{
+			message:  `pinging container registry registry.example.com: Get "https://registry.example.com/v2/": x509: certificate signed by unknown authority`,
+			expected: ReasonNetwork,
+		},
+		{
+			message:  "Back-off pulling image \"quay.io/org/image\"",
+			expected: ReasonUnknown,
+		},
+		{
+			expected: ReasonUnknown,
+		},
+	} {
+		t.Run(tc.message, func(t *testing.T) {
+			require.Equal(t, tc.expected, classifyFailure(tc.message))
+		})
+	}
+}
+
+func TestParseImage(t *testing.T) {
+	for _, tc := range []struct {
+		image      string
+		registry   string
+		repository string
+	}{
+		{image: "quay.io/org/image:tag", registry: "quay.io", repository: "quay.io/org/image"},
+		{image: "quay.io/org/image@sha256:0123", registry: "quay.io", repository: "quay.io/org/image"},
+		{image: "registry.example.com:5000/image", registry: "registry.example.com:5000", repository: "registry.example.com:5000/image"},
+		{image: "localhost/image:tag", registry: "localhost", repository: "localhost/image"},
+		{image: "busybox", registry: "docker.io", repository: "docker.io/library/busybox"},
+		{image: "org/image:tag", registry: "docker.io", repository: "docker.io/org/image"},
+		{image: "index.docker.io/org/image", registry: "docker.io", repository: "docker.io/org/image"},
+	} {
+		t.Run(tc.image, func(t *testing.T) {
+			registry, repository := parseImage(tc.image)
+			require.Equal(t, tc.registry, registry)
+			require.Equal(t, tc.repository, repository)
+		})
+	}
+}
+
+func TestTrimPod(t *testing.T) {
+	pod := makeTestPod("pod", "uid", makeTestStatus("app", "quay.io/org/image", reasonErrImagePull, "unauthorized"))
+	pod.Labels = map[string]string{"app": "app"}
+	pod.Spec.Containers = []corev1.Container{{Name: "app", Image: "quay.io/org/image"}}
+
+	trimmed, err := TrimPod(pod)
+	require.NoError(t, err)
+	require.Empty(t, trimmed.(*corev1.Pod).Labels)
+	require.Empty(t, trimmed.(*corev1.Pod).Spec.Containers)
+	require.Equal(t, pod.Status, trimmed.(*corev1.Pod).Status)
+	require.Equal(t, pod.UID, trimmed.(*corev1.Pod).UID)
+
+	secret := &corev1.Secret{}
+	trimmed, err = TrimPod(secret)
+	require.NoError(t, err)
+	require.Same(t, secret, trimmed)
+}
+
+func TestReconcileImagePull_Reconcile(t *testing.T) {
+	err := corev1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+	now := time.Now()
+
+	pullSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: pullsecret.PullSecretKey.Name, Namespace: pullsecret.PullSecretKey.Namespace},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths": {"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`),
+		},
+	}
+	objects := []client.Object{
+		// Credential of the pull secret is rejected, both containers count once
+		makeTestPod("quay", "quay-uid",
+			makeTestStatus("app", "quay.io/org/image:tag", reasonErrImagePull, "unauthorized: access to the requested resource is not authorized"),
+			makeTestStatus("sidecar", "quay.io/org/sidecar:tag", reasonErrImagePull, "unauthorized: authentication required"),
+		),
+		// Backing off on a registry without credential, the cause is in the last event
+		makeTestPod("private", "private-uid",
+			makeTestStatus("app", "registry.example.com/image", reasonImagePullBackOff, `Back-off pulling image "registry.example.com/image"`),
+		),
+		makeTestEvent("private.1", "private-uid",
+			`Failed to pull image "registry.example.com/image": dial tcp: i/o timeout`, now.Add(-time.Hour)),
+		makeTestEvent("private.2", "private-uid",
+			`Failed to pull image "registry.example.com/image": authentication required`, now),
+		makeTestEvent("other.1", "other-uid",
+			`Failed to pull image "registry.example.com/image": manifest unknown`, now.Add(time.Hour)),
+		// Unreachable registry
+		makeTestPod("offline", "offline-uid",
+			makeTestStatus("app", "registry.example.com/other", reasonErrImagePull, "dial tcp: lookup registry.example.com: no such host"),
+		),
+		// Backing off without event
+		makeTestPod("unknown", "unknown-uid",
+			makeTestStatus("app", "busybox", reasonImagePullBackOff, `Back-off pulling image "busybox"`),
+		),
+		// Running pods are no failures
+		makeTestPod("running", "running-uid", corev1.ContainerStatus{
+			Name:  "app",
+			Image: "quay.io/org/image:tag",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}),
+		makeTestPod("crashing", "crashing-uid",
+			makeTestStatus("app", "quay.io/org/image:tag", "CrashLoopBackOff", "back-off restarting failed container"),
+		),
+	}
+	collector := NewCollector()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(objects, pullSecret)...).
+		WithIndex(&corev1.Event{}, involvedObjectUIDField, indexInvolvedObjectUID).Build()
+	reconciler := ImagePullReconciler{
+		Client:    fakeClient,
+		Metrics:   collector,
+		ClusterId: testClusterId,
+	}
+	reconcile := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: pullsecret.PullSecretKey,
+		})
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	expected := `
+# HELP image_pull_failures Number of pods failing to pull an image from a registry, by cause of the failure and presence of a credential for the image in the cluster pull secret
+# TYPE image_pull_failures gauge
+image_pull_failures{_id="cluster-id",in_pull_secret="false",name="osd_exporter",reason="auth",registry="registry.example.com"} 1
+image_pull_failures{_id="cluster-id",in_pull_secret="false",name="osd_exporter",reason="network",registry="registry.example.com"} 1
+image_pull_failures{_id="cluster-id",in_pull_secret="false",name="osd_exporter",reason="unknown",registry="docker.io"} 1
+image_pull_failures{_id="cluster-id",in_pull_secret="true",name="osd_exporter",reason="auth",registry="quay.io"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	// Without pull secret no image has a credential
+	require.NoError(t, fakeClient.Delete(context.TODO(), pullSecret))
+	reconcile()
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(strings.ReplaceAll(expected,
+		`in_pull_secret="true"`, `in_pull_secret="false"`))))
+
+	// Recovered pods stop being reported
+	for _, obj := range objects {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			require.NoError(t, fakeClient.Delete(context.TODO(), pod))
+		}
+	}
+	reconcile()
+	require.Equal(t, 0, testutil.CollectAndCount(collector))
+}
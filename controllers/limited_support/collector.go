@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limited_support
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const collectorName = "limited_support"
+
+const limitedSupportMetric = "limited_support_enabled"
+
+var (
+	limitedSupportDesc = metrics.NewDesc(
+		limitedSupportMetric,
+		"Indicates if limited support is enabled",
+		metrics.ClusterIDLabel,
+	)
+	limitedSupportTransitionDesc = metrics.NewTransitionDesc(limitedSupportMetric, metrics.ClusterIDLabel)
+)
+
+// Collector exposes whether the cluster is in limited support
+type Collector struct {
+	metrics.ReconcileStatus
+
+	clusterId      string
+	limitedSupport metrics.BoolTransition
+	mutex          sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector(clusterId string) *Collector {
+	return &Collector{
+		clusterId: clusterId,
+	}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- limitedSupportDesc
+	ch <- limitedSupportTransitionDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ch <- metrics.NewGauge(limitedSupportDesc, metrics.BoolToFloat64(c.limitedSupport.Value()), c.clusterId)
+	c.limitedSupport.Collect(ch, limitedSupportTransitionDesc, c.clusterId)
+}
+
+func (c *Collector) SetLimitedSupport(uuid string, enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterId = uuid
+	c.limitedSupport.Set(enabled, time.Now())
+}
+
+// Reset forgets everything reported on limited support
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.limitedSupport.Reset()
+}
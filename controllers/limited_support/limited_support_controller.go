@@ -20,6 +20,7 @@ import (
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -40,9 +41,9 @@ var log = logf.Log.WithName(limitedSupportName)
 // LimitedSupportConfigMapReconciler reconciles a ConfigMap object
 type LimitedSupportConfigMapReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
 }
 
 // Reconcile reads that state of the cluster for a ConfigMap object limited-support and makes changes based the contained data
@@ -59,14 +60,14 @@ func (r *LimitedSupportConfigMapReconciler) Reconcile(ctx context.Context, req c
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			reqLogger.Info(fmt.Sprintf("Did not find ConfigMap %v", limitedSupportConfigMapName))
-			r.MetricsAggregator.SetLimitedSupport(r.ClusterId, false)
+			r.Metrics.SetLimitedSupport(r.ClusterId, false)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
 	reqLogger.Info(fmt.Sprintf("Found ConfigMap %v", limitedSupportConfigMapName))
-	r.MetricsAggregator.SetLimitedSupport(r.ClusterId, true)
+	r.Metrics.SetLimitedSupport(r.ClusterId, true)
 	return ctrl.Result{}, nil
 }
 
@@ -89,5 +90,8 @@ func (r *LimitedSupportConfigMapReconciler) SetupWithManager(mgr ctrl.Manager) e
 				return evt.Object.GetName() == limitedSupportConfigMapName && evt.Object.GetNamespace() == limitedSupportConfigMapNamespace
 			},
 		}).
-		Complete(r)
+		WatchesRawSource(r.Metrics.ResyncSource(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: limitedSupportConfigMapNamespace, Name: limitedSupportConfigMapName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
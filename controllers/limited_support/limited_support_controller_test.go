@@ -4,9 +4,7 @@ import (
 	"context"
 	"strings"
 	"testing"
-	"time"
 
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -41,18 +39,16 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 1
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, tc.clusterId)
-			done := metricsAggregator.Run()
-			defer close(done)
+			collector := NewCollector(tc.clusterId)
 			err := corev1.AddToScheme(scheme.Scheme)
 			require.NoError(t, err)
 
 			testConfigMap := makeTestConfigMap(limitedSupportConfigMapName, limitedSupportConfigMapNamespace)
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testConfigMap).Build()
 			reconciler := LimitedSupportConfigMapReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         tc.clusterId,
+				Client:    fakeClient,
+				Metrics:   collector,
+				ClusterId: tc.clusterId,
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -61,15 +57,12 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 1
 				},
 			})
 
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testCfgMap corev1.ConfigMap
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: limitedSupportConfigMapName, Namespace: limitedSupportConfigMapNamespace}, &testCfgMap)
 			require.NoError(t, err)
-			metric := metricsAggregator.GetLimitedsupportStatus()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedResults))
+			err = testutil.CollectAndCompare(collector, strings.NewReader(tc.expectedResults), "limited_support_enabled")
 			require.NoError(t, err)
 		})
 	}
@@ -90,18 +83,16 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 0
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, tc.clusterId)
-			done := metricsAggregator.Run()
-			defer close(done)
+			collector := NewCollector(tc.clusterId)
 			err := corev1.AddToScheme(scheme.Scheme)
 			require.NoError(t, err)
 
 			testConfigMap := makeTestConfigMap(limitedSupportConfigMapName, "default")
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testConfigMap).Build()
 			reconciler := LimitedSupportConfigMapReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         tc.clusterId,
+				Client:    fakeClient,
+				Metrics:   collector,
+				ClusterId: tc.clusterId,
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -110,15 +101,12 @@ limited_support_enabled{_id="i-am-a-cluster-id",name="osd_exporter"} 0
 				},
 			})
 
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testCfgMap corev1.ConfigMap
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: limitedSupportConfigMapName, Namespace: "default"}, &testCfgMap)
 			require.NoError(t, err)
-			metric := metricsAggregator.GetLimitedsupportStatus()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedResults))
+			err = testutil.CollectAndCompare(collector, strings.NewReader(tc.expectedResults), "limited_support_enabled")
 			require.NoError(t, err)
 		})
 	}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const collectorName = "machine"
+
+var podsPreventingNodeDrainDesc = prometheus.NewDesc(
+	"pods_preventing_node_drain",
+	"Pods that cannot be drained from a deleting machine",
+	[]string{"pod_name", "pod_namespace", "instance", "node", "machine"}, nil,
+)
+
+type drainingMachine struct {
+	nodeName      string
+	podNamespaces map[string]string
+	// lastConfirmed is the last time the reconciler observed the machine failing to drain
+	lastConfirmed time.Time
+}
+
+// Collector exposes the customer pods preventing deleting machines from draining
+type Collector struct {
+	metrics.ReconcileStatus
+
+	drainingMachines map[string]drainingMachine
+	mutex            sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Sweeper   = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{
+		drainingMachines: map[string]drainingMachine{},
+	}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- podsPreventingNodeDrainDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for machine, machineInfo := range c.drainingMachines {
+		for podName, podNamespace := range machineInfo.podNamespaces {
+			ch <- metrics.NewGauge(podsPreventingNodeDrainDesc, 1,
+				podName, podNamespace, machineInfo.nodeName, machineInfo.nodeName, machine)
+		}
+	}
+}
+
+// SetFailingDrainPodsForMachine replaces the pods failing to drain from the machine.
+// Because we might have multiple machines in this state and the machine controller reconciles
+// a single machine at a time, the collector keeps a map of the machines with their failing pods.
+func (c *Collector) SetFailingDrainPodsForMachine(machineName string, podNamespaceMap map[string]string, nodeName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.drainingMachines[machineName] = drainingMachine{
+		nodeName:      nodeName,
+		podNamespaces: podNamespaceMap,
+		lastConfirmed: time.Now(),
+	}
+}
+
+func (c *Collector) RemoveMachineMetrics(machineName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.drainingMachines, machineName)
+}
+
+// Sweep drops the machines which have not been confirmed failing to drain since expiredBefore,
+// e.g. because the delete event of the machine was missed
+func (c *Collector) Sweep(expiredBefore time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for machineName, machineInfo := range c.drainingMachines {
+		if machineInfo.lastConfirmed.Before(expiredBefore) {
+			logf.Log.WithName(logName).Info("Dropping stale drain metrics", "machine", machineName, "lastConfirmed", machineInfo.lastConfirmed)
+			delete(c.drainingMachines, machineName)
+		}
+	}
+}
+
+// Reset forgets everything reported on all machines
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.drainingMachines = map[string]drainingMachine{}
+}
@@ -18,9 +18,11 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
 	"github.com/openshift/osd-metrics-exporter/controllers/utils"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
@@ -36,12 +38,12 @@ const (
 	machineNamespace = "openshift-machine-api"
 	logName          = "controller_machine"
 
-	// timeBuffer is the delay between when a machine is deleted to when we want to care
+	// defaultTimeBuffer is the delay between when a machine is deleted to when we want to care
 	// if a customer's pod is not draining before we start emitting metrics. We don't
 	// want to set this too low so that it starts emitting metrics before the pods actually
 	// have a chance to drain and reschedule, but want to set this to a time where it
 	// might be considered a problem for the node to not be draining properly.
-	timeBuffer = 15 * time.Minute
+	defaultTimeBuffer = 15 * time.Minute
 
 	// defaultDelayInterval is the default time to requeue a machine that's being evaluated
 	// so that it can be evaluated again when there's no active metrics being fired.
@@ -61,9 +63,13 @@ var (
 // MachineReconciler reconciles a Machine object
 type MachineReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
+
+	// drainTimeBuffer overrides the delay after the deletion of a machine before pods failing to drain are reported
+	drainTimeBuffer time.Duration
+	configMutex     sync.Mutex
 }
 
 // Reconcile reads that state of the cluster for machine objects and makes changes based the contained data
@@ -82,7 +88,7 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Machine not found. Ensuring that no metrics for this machine are leftover")
 			// use the req.Name here because the Machine does not exist and will be nil
-			r.MetricsAggregator.RemoveMachineMetrics(req.Name)
+			r.Metrics.RemoveMachineMetrics(req.Name)
 			return utils.DoNotRequeue()
 		}
 		reqLogger.Error(err, "An error occurred getting the machine")
@@ -96,6 +102,25 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	return utils.DoNotRequeue()
 }
 
+// ApplyConfig applies the machine settings of the exporter configuration to the following reconciles
+func (r *MachineReconciler) ApplyConfig(spec *v1alpha1.MetricsExporterConfigSpec) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+	r.drainTimeBuffer = 0
+	if spec.Machine != nil && spec.Machine.DrainTimeBuffer != nil {
+		r.drainTimeBuffer = spec.Machine.DrainTimeBuffer.Duration
+	}
+}
+
+func (r *MachineReconciler) timeBuffer() time.Duration {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+	if r.drainTimeBuffer > 0 {
+		return r.drainTimeBuffer
+	}
+	return defaultTimeBuffer
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	indexerFunc := func(rawObj client.Object) []string {
@@ -109,7 +134,7 @@ func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&machinev1beta1.Machine{}).
-		Complete(r)
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
 
 func getMostRecentDrainFailedEvent(eventList *corev1.EventList) (*corev1.Event, error) {
@@ -183,10 +208,10 @@ func (r *MachineReconciler) evaluateDeletingMachine(ctx context.Context, machine
 	reqLogger := logf.FromContext(ctx).WithName(logName)
 
 	// Check Deleting Timestamp.
-	// If it's been less than the timeBuffer we don't care, requeue for the default delay interval.
+	// If it's been less than the time buffer we don't care, requeue for the default delay interval.
 	deletedTime := machine.GetDeletionTimestamp().Time
 	now := time.Now()
-	if !deletedTime.Before(now.Add(-timeBuffer)) {
+	if !deletedTime.Before(now.Add(-r.timeBuffer())) {
 		reqLogger.Info("Machine was not deleted long enough ago. Requeueing after 5m.")
 		return utils.RequeueAfter(defaultDelayInterval)
 	}
@@ -233,7 +258,7 @@ func (r *MachineReconciler) evaluateDeletingMachine(ctx context.Context, machine
 	reqLogger.Info("The following non-OpenShift pods are failing to drain from the machine", "node", nodeName, "pods/namespaces", podNamespaces)
 
 	// Update the metrics for this machine
-	r.MetricsAggregator.SetFailingDrainPodsForMachine(machine.Name, podNamespaces, nodeName)
+	r.Metrics.SetFailingDrainPodsForMachine(machine.Name, podNamespaces, nodeName)
 
 	// Requeue every two minutes, even though the event might not be updated for ~10m we'd rather
 	// retry every few minutes to catch the new event within a few cycles than potentially only
@@ -101,4 +101,19 @@ var _ = ginkgo.Describe("MachineController", func() {
 			})
 		})
 	})
+
+	ginkgo.Context("When sweeping stale machine metrics", func() {
+		ginkgo.It("drops only the machines not confirmed since the expiry", func() {
+			collector := NewCollector()
+			collector.SetFailingDrainPodsForMachine("stale", map[string]string{"foo": "bar"}, "stale-node")
+			expiredBefore := time.Now().Add(time.Millisecond)
+			time.Sleep(2 * time.Millisecond)
+			collector.SetFailingDrainPodsForMachine("fresh", map[string]string{"baz": "bat"}, "fresh-node")
+
+			collector.Sweep(expiredBefore)
+
+			gomega.Expect(collector.drainingMachines).To(gomega.HaveLen(1))
+			gomega.Expect(collector.drainingMachines).To(gomega.HaveKey("fresh"))
+		})
+	})
 })
@@ -0,0 +1,300 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauth
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName = "oauth"
+	providerLabel = "provider"
+
+	idpLabel           = "idp"
+	typeLabel          = "type"
+	mappingMethodLabel = "mapping_method"
+	insecureLabel      = "insecure"
+	missingCALabel     = "missing_ca"
+	unrestrictedLabel  = "unrestricted"
+	fieldLabel         = "field"
+	schemeLabel        = "scheme"
+	templateLabel      = "template"
+)
+
+var knownIdentityProviderTypes = []configv1.IdentityProviderType{
+	configv1.IdentityProviderTypeBasicAuth,
+	configv1.IdentityProviderTypeGitHub,
+	configv1.IdentityProviderTypeGitLab,
+	configv1.IdentityProviderTypeGoogle,
+	configv1.IdentityProviderTypeHTPasswd,
+	configv1.IdentityProviderTypeKeystone,
+	configv1.IdentityProviderTypeLDAP,
+	configv1.IdentityProviderTypeOpenID,
+	configv1.IdentityProviderTypeRequestHeader,
+}
+
+// otherIdentityProviderType is the type identity providers of a type missing from knownIdentityProviderTypes
+// are counted as, e.g. types introduced by newer OpenShift releases
+const otherIdentityProviderType configv1.IdentityProviderType = "other"
+
+// isKnownIdentityProviderType returns whether t is reported on its own by identity_provider
+func isKnownIdentityProviderType(t configv1.IdentityProviderType) bool {
+	for _, known := range knownIdentityProviderTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+type providerKey struct {
+	name      string
+	namespace string
+}
+
+// clusterOAuthKey is the key of the cluster OAuth resource, the only one configuring the OAuth server
+var clusterOAuthKey = providerKey{name: clusterOAuthName}
+
+// orderedKeys returns the keys of m, the cluster OAuth resource first and the others by namespace and name
+func orderedKeys[V any](m map[providerKey]V) []providerKey {
+	keys := make([]providerKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == clusterOAuthKey) != (keys[j] == clusterOAuthKey) {
+			return keys[i] == clusterOAuthKey
+		}
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].name < keys[j].name
+	})
+	return keys
+}
+
+var (
+	identityProviderDesc = metrics.NewDesc(
+		"identity_provider",
+		"Indicates if an identity provider is enabled",
+		providerLabel,
+	)
+	identityProviderInfoDesc = metrics.NewDesc(
+		"identity_provider_info",
+		"Configuration of an identity provider, with flags of risky settings",
+		idpLabel, typeLabel, mappingMethodLabel, insecureLabel, missingCALabel, unrestrictedLabel,
+	)
+	identityProviderReferenceValidDesc = metrics.NewDesc(
+		"identity_provider_reference_valid",
+		"Indicates if a Secret or ConfigMap referenced from an identity provider exists in openshift-config with the key it reads",
+		idpLabel, fieldLabel,
+	)
+	htpasswdUsersDesc = metrics.NewDesc(
+		"identity_provider_htpasswd_users",
+		"Number of users of an HTPasswd identity provider",
+		idpLabel,
+	)
+	htpasswdWeakHashesDesc = metrics.NewDesc(
+		"identity_provider_htpasswd_weak_hashes",
+		"Number of users of an HTPasswd identity provider whose password is hashed with a scheme other than bcrypt",
+		idpLabel, schemeLabel,
+	)
+	htpasswdMalformedLinesDesc = metrics.NewDesc(
+		"identity_provider_htpasswd_malformed_lines",
+		"Number of lines of the htpasswd file of an HTPasswd identity provider which are not a user and a hash",
+		idpLabel,
+	)
+	accessTokenMaxAgeDesc = metrics.NewDesc(
+		"oauth_access_token_max_age_seconds",
+		"Maximum age of the access tokens granted by the OAuth server",
+	)
+	accessTokenInactivityTimeoutDesc = metrics.NewDesc(
+		"oauth_access_token_inactivity_timeout_seconds",
+		"Inactivity timeout of the access tokens granted by any client, 0 if tokens are valid until their lifetime",
+	)
+	templateCustomizedDesc = metrics.NewDesc(
+		"oauth_template_customized",
+		"Indicates if a page of the OAuth server is rendered from a custom template",
+		templateLabel,
+	)
+)
+
+// templates are the templates reported by oauth_template_customized
+var templates = []string{templateLogin, templateProviderSelection, templateError}
+
+// Collector exposes the identity providers configured in the OAuth resources
+type Collector struct {
+	metrics.ReconcileStatus
+
+	providerMap map[providerKey][]identityProvider
+	references  map[providerKey][]ReferenceStatus
+	htpasswd    map[providerKey][]HTPasswdInventory
+	policies    map[providerKey]sessionPolicy
+	mutex       sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{
+		providerMap: make(map[providerKey][]identityProvider),
+		references:  make(map[providerKey][]ReferenceStatus),
+		htpasswd:    make(map[providerKey][]HTPasswdInventory),
+		policies:    make(map[providerKey]sessionPolicy),
+	}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- identityProviderDesc
+	ch <- identityProviderInfoDesc
+	ch <- identityProviderReferenceValidDesc
+	ch <- htpasswdUsersDesc
+	ch <- htpasswdWeakHashesDesc
+	ch <- htpasswdMalformedLinesDesc
+	ch <- accessTokenMaxAgeDesc
+	ch <- accessTokenInactivityTimeoutDesc
+	ch <- templateCustomizedDesc
+}
+
+// Collect reports the number of configured identity providers per type, and every identity provider.
+// Identity providers of unknown types are counted as other, their type is reported by identity_provider_info.
+// The token config and templates are those of the cluster OAuth resource, the one configuring the OAuth
+// server. Identity providers are named uniquely in an OAuth resource. When several OAuth resources define
+// an identity provider of the same name, the one of the cluster OAuth resource is reported, otherwise the
+// one of the first resource by namespace and name.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	providers := make(map[configv1.IdentityProviderType]int)
+	reported := make(map[string]bool)
+	for _, key := range orderedKeys(c.providerMap) {
+		for _, p := range c.providerMap[key] {
+			if isKnownIdentityProviderType(p.providerType) {
+				providers[p.providerType] += 1
+			} else {
+				providers[otherIdentityProviderType] += 1
+			}
+			if reported[p.name] {
+				continue
+			}
+			reported[p.name] = true
+			ch <- metrics.NewGauge(identityProviderInfoDesc, 1, p.name, string(p.providerType), string(p.mappingMethod),
+				strconv.FormatBool(p.insecure), strconv.FormatBool(p.missingCA), strconv.FormatBool(p.unrestricted))
+		}
+	}
+
+	for _, t := range knownIdentityProviderTypes {
+		ch <- metrics.NewGauge(identityProviderDesc, float64(providers[t]), string(t))
+	}
+	ch <- metrics.NewGauge(identityProviderDesc, float64(providers[otherIdentityProviderType]), string(otherIdentityProviderType))
+
+	reportedReferences := make(map[ReferenceStatus]bool)
+	for _, key := range orderedKeys(c.references) {
+		for _, ref := range c.references[key] {
+			key := ReferenceStatus{IDP: ref.IDP, Field: ref.Field}
+			if reportedReferences[key] {
+				continue
+			}
+			reportedReferences[key] = true
+			ch <- metrics.NewGauge(identityProviderReferenceValidDesc, metrics.BoolToFloat64(ref.Valid), ref.IDP, ref.Field)
+		}
+	}
+
+	reportedHTPasswd := make(map[string]bool)
+	for _, key := range orderedKeys(c.htpasswd) {
+		for _, inventory := range c.htpasswd[key] {
+			if reportedHTPasswd[inventory.IDP] {
+				continue
+			}
+			reportedHTPasswd[inventory.IDP] = true
+			ch <- metrics.NewGauge(htpasswdUsersDesc, float64(inventory.Users), inventory.IDP)
+			for _, scheme := range weakHashSchemes {
+				ch <- metrics.NewGauge(htpasswdWeakHashesDesc, float64(inventory.WeakHashes[scheme]), inventory.IDP, scheme)
+			}
+			ch <- metrics.NewGauge(htpasswdMalformedLinesDesc, float64(inventory.Malformed), inventory.IDP)
+		}
+	}
+
+	if policy, ok := c.policies[clusterOAuthKey]; ok {
+		ch <- metrics.NewGauge(accessTokenMaxAgeDesc, policy.accessTokenMaxAge.Seconds())
+		ch <- metrics.NewGauge(accessTokenInactivityTimeoutDesc, policy.accessTokenInactivityTimeout.Seconds())
+		for _, template := range templates {
+			ch <- metrics.NewGauge(templateCustomizedDesc, metrics.BoolToFloat64(policy.templates[template]), template)
+		}
+	}
+}
+
+func (c *Collector) SetOAuthIDP(name, namespace string, provider []configv1.IdentityProvider) {
+	providers := make([]identityProvider, len(provider))
+	for i, p := range provider {
+		providers[i] = newIdentityProvider(p)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.providerMap[providerKey{name: name, namespace: namespace}] = providers
+}
+
+// SetOAuthIDPReferences replaces the resolved references of the identity providers of an OAuth resource
+func (c *Collector) SetOAuthIDPReferences(name, namespace string, references []ReferenceStatus) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.references[providerKey{name: name, namespace: namespace}] = references
+}
+
+// SetOAuthIDPHTPasswd replaces the inventories of the HTPasswd identity providers of an OAuth resource
+func (c *Collector) SetOAuthIDPHTPasswd(name, namespace string, inventories []HTPasswdInventory) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.htpasswd[providerKey{name: name, namespace: namespace}] = inventories
+}
+
+// SetOAuthSessionPolicy replaces the token config and templates of an OAuth resource
+func (c *Collector) SetOAuthSessionPolicy(name, namespace string, spec configv1.OAuthSpec) {
+	policy := newSessionPolicy(spec)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.policies[providerKey{name: name, namespace: namespace}] = policy
+}
+
+func (c *Collector) DeleteOAuthIDP(name, namespace string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.providerMap, providerKey{name: name, namespace: namespace})
+	delete(c.references, providerKey{name: name, namespace: namespace})
+	delete(c.htpasswd, providerKey{name: name, namespace: namespace})
+	delete(c.policies, providerKey{name: name, namespace: namespace})
+}
+
+// Reset forgets everything reported on all OAuth resources
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.providerMap = make(map[providerKey][]identityProvider)
+	c.references = make(map[providerKey][]ReferenceStatus)
+	c.htpasswd = make(map[providerKey][]HTPasswdInventory)
+	c.policies = make(map[providerKey]sessionPolicy)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauth
+
+import (
+	"context"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Hash schemes of htpasswd entries, every scheme but bcrypt is weak
+const (
+	hashBcrypt  = "bcrypt"
+	hashSHA1    = "sha1"
+	hashMD5     = "md5"
+	hashCrypt   = "crypt"
+	hashUnknown = "unknown"
+)
+
+// weakHashSchemes are reported for every HTPasswd identity provider, including unused ones
+var weakHashSchemes = []string{hashSHA1, hashMD5, hashCrypt, hashUnknown}
+
+// cryptAlphabet are the characters of a traditional DES crypt hash
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// HTPasswdInventory summarizes the users of an HTPasswd identity provider, without the users themselves
+type HTPasswdInventory struct {
+	IDP   string
+	Users int
+	// WeakHashes is the number of users per weak hash scheme
+	WeakHashes map[string]int
+	// Malformed is the number of non-empty lines which are not a user and a hash
+	Malformed int
+}
+
+// parseHTPasswd returns the inventory of the htpasswd file data. Like the OAuth server, blank lines
+// are skipped and a user listed more than once is counted once, with its last hash.
+func parseHTPasswd(data []byte) HTPasswdInventory {
+	inventory := HTPasswdInventory{WeakHashes: map[string]int{}}
+	hashes := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		user, hash, found := strings.Cut(line, ":")
+		if !found || user == "" || hash == "" {
+			inventory.Malformed++
+			continue
+		}
+		hashes[user] = hash
+	}
+	inventory.Users = len(hashes)
+	for _, hash := range hashes {
+		if scheme := hashScheme(hash); scheme != hashBcrypt {
+			inventory.WeakHashes[scheme]++
+		}
+	}
+	return inventory
+}
+
+// hashScheme returns the scheme of an htpasswd hash
+func hashScheme(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return hashBcrypt
+	case strings.HasPrefix(hash, "{SHA}"):
+		return hashSHA1
+	case strings.HasPrefix(hash, "$apr1$"), strings.HasPrefix(hash, "$1$"):
+		return hashMD5
+	case len(hash) == 13 && strings.Trim(hash, cryptAlphabet) == "":
+		return hashCrypt
+	}
+	return hashUnknown
+}
+
+// htpasswdInventories returns the inventory of every HTPasswd identity provider whose file data
+// can be read, invalid references are reported by resolveReferences
+func (r *OAuthReconciler) htpasswdInventories(ctx context.Context, idps []configv1.IdentityProvider) ([]HTPasswdInventory, error) {
+	var inventories []HTPasswdInventory
+	for _, idp := range idps {
+		if idp.Type != configv1.IdentityProviderTypeHTPasswd || idp.HTPasswd == nil || idp.HTPasswd.FileData.Name == "" {
+			continue
+		}
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: configNamespace, Name: idp.HTPasswd.FileData.Name}
+		if err := r.Get(ctx, key, secret); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		data, ok := secret.Data[htpasswdKey]
+		if !ok {
+			continue
+		}
+		inventory := parseHTPasswd(data)
+		inventory.IDP = idp.Name
+		if inventory.Malformed > 0 {
+			log.Info("HTPasswd identity provider has malformed lines", "idp", idp.Name, "malformed", inventory.Malformed)
+		}
+		inventories = append(inventories, inventory)
+	}
+	return inventories, nil
+}
@@ -19,23 +19,34 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/osd-metrics-exporter/controllers/utils"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var log = logf.Log.WithName("controller_oauth")
 
-const finalizer = "finalizers.osd.metrics.exporter.openshift.io"
+const (
+	finalizer = "finalizers.osd.metrics.exporter.openshift.io"
+	// clusterOAuthName is the name of the cluster wide OAuth configuration
+	clusterOAuthName = "cluster"
+)
 
 // OAuthReconciler reconciles a OAuth object
 type OAuthReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
+	Scheme  *runtime.Scheme
+	Metrics *Collector
 }
 
 // Reconcile reads that state of the cluster for a OAuth object and makes changes based on the state read
@@ -69,7 +80,23 @@ func (r *OAuthReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				return ctrl.Result{}, err
 			}
 		}
-		r.MetricsAggregator.SetOAuthIDP(instance.Name, instance.Namespace, instance.Spec.IdentityProviders)
+		for _, idp := range instance.Spec.IdentityProviders {
+			if !isKnownIdentityProviderType(idp.Type) {
+				reqLogger.Info("Identity provider of unknown type is counted as other", "idp", idp.Name, "type", idp.Type)
+			}
+		}
+		r.Metrics.SetOAuthIDP(instance.Name, instance.Namespace, instance.Spec.IdentityProviders)
+		r.Metrics.SetOAuthSessionPolicy(instance.Name, instance.Namespace, instance.Spec)
+		references, err := r.resolveReferences(ctx, instance.Spec.IdentityProviders)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Metrics.SetOAuthIDPReferences(instance.Name, instance.Namespace, references)
+		inventories, err := r.htpasswdInventories(ctx, instance.Spec.IdentityProviders)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Metrics.SetOAuthIDPHTPasswd(instance.Name, instance.Namespace, inventories)
 	} else {
 		if utils.ContainsString(instance.Finalizers, finalizer) {
 			controllerutil.RemoveFinalizer(instance, finalizer)
@@ -77,15 +104,54 @@ func (r *OAuthReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				return ctrl.Result{}, err
 			}
 		}
-		r.MetricsAggregator.DeleteOAuthIDP(instance.Name, instance.Namespace)
+		r.Metrics.DeleteOAuthIDP(instance.Name, instance.Namespace)
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// referencingOAuths returns the requests of the OAuth resources whose identity providers reference obj
+func (r *OAuthReconciler) referencingOAuths(ctx context.Context, obj client.Object) []reconcile.Request {
+	oauths := &configv1.OAuthList{}
+	if err := r.List(ctx, oauths); err != nil {
+		log.Error(err, "Failed to list the OAuth resources referencing an object", "object", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, oauth := range oauths.Items {
+		if referencesObject(oauth.Spec.IdentityProviders, obj) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: oauth.Namespace, Name: oauth.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// referencesObject returns whether any of idps references obj
+func referencesObject(idps []configv1.IdentityProvider, obj client.Object) bool {
+	for _, idp := range idps {
+		for _, ref := range identityProviderReferences(idp.IdentityProviderConfig) {
+			if ref.matches(obj) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *OAuthReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	referenced := handler.EnqueueRequestsFromMapFunc(r.referencingOAuths)
+	inConfigNamespace := builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == configNamespace
+	}))
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&configv1.OAuth{}).
-		Complete(r)
+		Watches(&corev1.ConfigMap{}, referenced, inConfigNamespace).
+		Watches(&corev1.Secret{}, referenced, inConfigNamespace).
+		WatchesRawSource(r.Metrics.ResyncSource(&configv1.OAuth{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterOAuthName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
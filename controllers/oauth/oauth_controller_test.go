@@ -15,25 +15,32 @@ package oauth
 
 import (
 	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 
 	configv1 "github.com/openshift/api/config/v1"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
-	providerLabel = "provider"
 	testName      = "test"
 	testNamespace = "test"
 )
@@ -88,11 +95,21 @@ func TestReconcileOAuth_Reconcile(t *testing.T) {
 				configv1.IdentityProviderTypeBasicAuth: 0,
 			},
 		},
+		{
+			name: "unknown types",
+			providers: []configv1.IdentityProviderType{
+				configv1.IdentityProviderTypeGitHub,
+				"Kerberos",
+				"SAML",
+			},
+			expectedResult: map[configv1.IdentityProviderType]int{
+				configv1.IdentityProviderTypeGitHub: 1,
+				otherIdentityProviderType:           2,
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, "cluster-id")
-			done := metricsAggregator.Run()
-			defer close(done)
+			collector := NewCollector()
 			err := configv1.Install(scheme.Scheme)
 			require.NoError(t, err)
 			if tc.existingProviders == nil {
@@ -104,8 +121,8 @@ func TestReconcileOAuth_Reconcile(t *testing.T) {
 			testOAuthCR := makeTestOAuth(testName, testNamespace, tc.existingProviders...)
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testOAuthCR).Build()
 			reconciler := OAuthReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
+				Client:  fakeClient,
+				Metrics: collector,
 			}
 
 			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
@@ -143,19 +160,579 @@ func TestReconcileOAuth_Reconcile(t *testing.T) {
 			})
 
 			// Validate our metrics reflect the changes to the OAuth IdentityProviderType list
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testOAuth configv1.OAuth
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: testName, Namespace: testNamespace}, &testOAuth)
 			require.NoError(t, err)
 			require.Contains(t, testOAuth.Finalizers, finalizer)
-			metric := metricsAggregator.GetIdentityProviderMetric()
-			for p, v := range tc.expectedResult {
-				val := testutil.ToFloat64(metric.With(prometheus.Labels{providerLabel: string(p)}))
-				require.EqualValues(t, v, val, "provider label: %s", string(p))
+			expected := `
+# HELP identity_provider Indicates if an identity provider is enabled
+# TYPE identity_provider gauge
+`
+			for _, p := range knownIdentityProviderTypes {
+				expected += fmt.Sprintf("identity_provider{name=\"osd_exporter\",provider=%q} %d\n", p, tc.expectedResult[p])
+			}
+			expected += fmt.Sprintf("identity_provider{name=\"osd_exporter\",provider=\"other\"} %d\n",
+				tc.expectedResult[otherIdentityProviderType])
+			err = testutil.CollectAndCompare(collector, strings.NewReader(expected), "identity_provider")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewIdentityProvider(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		idp      configv1.IdentityProviderConfig
+		expected identityProvider
+	}{
+		{
+			name: "ldaps",
+			idp: configv1.IdentityProviderConfig{
+				Type: configv1.IdentityProviderTypeLDAP,
+				LDAP: &configv1.LDAPIdentityProvider{URL: "ldaps://ldap.example.com/ou=users"},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeLDAP},
+		},
+		{
+			name: "ldap without TLS",
+			idp: configv1.IdentityProviderConfig{
+				Type: configv1.IdentityProviderTypeLDAP,
+				LDAP: &configv1.LDAPIdentityProvider{URL: "ldap://ldap.example.com/ou=users"},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeLDAP, insecure: true},
+		},
+		{
+			name: "insecure ldaps",
+			idp: configv1.IdentityProviderConfig{
+				Type: configv1.IdentityProviderTypeLDAP,
+				LDAP: &configv1.LDAPIdentityProvider{URL: "LDAPS://ldap.example.com", Insecure: true},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeLDAP, insecure: true},
+		},
+		{
+			name: "openid without CA",
+			idp: configv1.IdentityProviderConfig{
+				Type:   configv1.IdentityProviderTypeOpenID,
+				OpenID: &configv1.OpenIDIdentityProvider{},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeOpenID, missingCA: true},
+		},
+		{
+			name: "openid with CA",
+			idp: configv1.IdentityProviderConfig{
+				Type:   configv1.IdentityProviderTypeOpenID,
+				OpenID: &configv1.OpenIDIdentityProvider{CA: configv1.ConfigMapNameReference{Name: "ca"}},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeOpenID},
+		},
+		{
+			name: "request header without client CA",
+			idp: configv1.IdentityProviderConfig{
+				Type:          configv1.IdentityProviderTypeRequestHeader,
+				RequestHeader: &configv1.RequestHeaderIdentityProvider{},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeRequestHeader, missingCA: true},
+		},
+		{
+			name: "github without restriction",
+			idp: configv1.IdentityProviderConfig{
+				Type:   configv1.IdentityProviderTypeGitHub,
+				GitHub: &configv1.GitHubIdentityProvider{},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeGitHub, unrestricted: true},
+		},
+		{
+			name: "github restricted to teams",
+			idp: configv1.IdentityProviderConfig{
+				Type:   configv1.IdentityProviderTypeGitHub,
+				GitHub: &configv1.GitHubIdentityProvider{Teams: []string{"org/team"}},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeGitHub},
+		},
+		{
+			name: "gitlab",
+			idp: configv1.IdentityProviderConfig{
+				Type:   configv1.IdentityProviderTypeGitLab,
+				GitLab: &configv1.GitLabIdentityProvider{},
+			},
+			expected: identityProvider{providerType: configv1.IdentityProviderTypeGitLab, unrestricted: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expected.name = tc.name
+			tc.expected.mappingMethod = configv1.MappingMethodClaim
+			require.Equal(t, tc.expected, newIdentityProvider(configv1.IdentityProvider{
+				Name:                   tc.name,
+				IdentityProviderConfig: tc.idp,
+			}))
+		})
+	}
+}
+
+func TestCollector_IdentityProviderInfo(t *testing.T) {
+	collector := NewCollector()
+	collector.SetOAuthIDP(testName, testNamespace, []configv1.IdentityProvider{
+		{
+			Name:          "corp-ldap",
+			MappingMethod: configv1.MappingMethodLookup,
+			IdentityProviderConfig: configv1.IdentityProviderConfig{
+				Type: configv1.IdentityProviderTypeLDAP,
+				LDAP: &configv1.LDAPIdentityProvider{URL: "ldap://ldap.example.com"},
+			},
+		},
+		{
+			Name: "github",
+			IdentityProviderConfig: configv1.IdentityProviderConfig{
+				Type:   configv1.IdentityProviderTypeGitHub,
+				GitHub: &configv1.GitHubIdentityProvider{Organizations: []string{"org"}},
+			},
+		},
+	})
+	expected := `
+# HELP identity_provider_info Configuration of an identity provider, with flags of risky settings
+# TYPE identity_provider_info gauge
+identity_provider_info{idp="corp-ldap",insecure="true",mapping_method="lookup",missing_ca="false",name="osd_exporter",type="LDAP",unrestricted="false"} 1
+identity_provider_info{idp="github",insecure="false",mapping_method="claim",missing_ca="false",name="osd_exporter",type="GitHub",unrestricted="false"} 1
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "identity_provider_info")
+	require.NoError(t, err)
+
+	collector.DeleteOAuthIDP(testName, testNamespace)
+	require.Equal(t, 0, testutil.CollectAndCount(collector, "identity_provider_info"))
+}
+
+func makeTestReferencingOAuth() *configv1.OAuth {
+	return &configv1.OAuth{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.OAuthSpec{
+			IdentityProviders: []configv1.IdentityProvider{
+				{
+					Name: "github",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						Type: configv1.IdentityProviderTypeGitHub,
+						GitHub: &configv1.GitHubIdentityProvider{
+							ClientSecret: configv1.SecretNameReference{Name: "github-secret"},
+							CA:           configv1.ConfigMapNameReference{Name: "github-ca"},
+						},
+					},
+				},
+				{
+					Name: "ldap",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						Type: configv1.IdentityProviderTypeLDAP,
+						LDAP: &configv1.LDAPIdentityProvider{
+							BindPassword: configv1.SecretNameReference{Name: "ldap-bind"},
+						},
+					},
+				},
+				{
+					Name: "htpasswd",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						Type:     configv1.IdentityProviderTypeHTPasswd,
+						HTPasswd: &configv1.HTPasswdIdentityProvider{FileData: configv1.SecretNameReference{Name: "htpasswd"}},
+					},
+				},
+				{
+					Name: "proxy",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						Type: configv1.IdentityProviderTypeRequestHeader,
+						RequestHeader: &configv1.RequestHeaderIdentityProvider{
+							ClientCA: configv1.ConfigMapNameReference{Name: "github-ca"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestReconcileOAuthReferences_Reconcile(t *testing.T) {
+	err := configv1.Install(scheme.Scheme)
+	require.NoError(t, err)
+
+	htpasswd := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "htpasswd", Namespace: configNamespace},
+		Data:       map[string][]byte{htpasswdKey: []byte("user:$2y$05$abcdefghijklmnopqrstuv")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		makeTestReferencingOAuth(),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "github-secret", Namespace: configNamespace},
+			Data:       map[string][]byte{clientSecretKey: []byte("secret")},
+		},
+		// The CA is under the wrong key
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "github-ca", Namespace: configNamespace},
+			Data:       map[string]string{"ca-bundle.crt": "-----BEGIN CERTIFICATE-----"},
+		},
+		// The bind password is in another namespace
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ldap-bind", Namespace: "default"},
+			Data:       map[string][]byte{bindPasswordKey: []byte("password")},
+		},
+		htpasswd,
+	).Build()
+	collector := NewCollector()
+	reconciler := OAuthReconciler{
+		Client:  fakeClient,
+		Metrics: collector,
+	}
+	reconcile := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}})
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	expected := `
+# HELP identity_provider_reference_valid Indicates if a Secret or ConfigMap referenced from an identity provider exists in openshift-config with the key it reads
+# TYPE identity_provider_reference_valid gauge
+identity_provider_reference_valid{field="bindPassword",idp="ldap",name="osd_exporter"} 0
+identity_provider_reference_valid{field="ca",idp="github",name="osd_exporter"} 0
+identity_provider_reference_valid{field="clientCA",idp="proxy",name="osd_exporter"} 0
+identity_provider_reference_valid{field="clientSecret",idp="github",name="osd_exporter"} 1
+identity_provider_reference_valid{field="fileData",idp="htpasswd",name="osd_exporter"} 1
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected), "identity_provider_reference_valid")
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Delete(context.TODO(), htpasswd))
+	reconcile()
+	expected = strings.Replace(expected,
+		`identity_provider_reference_valid{field="fileData",idp="htpasswd",name="osd_exporter"} 1`,
+		`identity_provider_reference_valid{field="fileData",idp="htpasswd",name="osd_exporter"} 0`, 1)
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected), "identity_provider_reference_valid")
+	require.NoError(t, err)
+}
+
+func TestReferencingOAuths(t *testing.T) {
+	err := configv1.Install(scheme.Scheme)
+	require.NoError(t, err)
+	reconciler := OAuthReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(makeTestReferencingOAuth()).Build(),
+	}
+	cluster := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "cluster"}}}
+
+	for _, tc := range []struct {
+		name     string
+		obj      client.Object
+		expected []reconcile.Request
+	}{
+		{
+			name:     "referenced secret",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ldap-bind", Namespace: configNamespace}},
+			expected: cluster,
+		},
+		{
+			name:     "referenced configmap",
+			obj:      &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "github-ca", Namespace: configNamespace}},
+			expected: cluster,
+		},
+		{
+			name: "configmap named as a referenced secret",
+			obj:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "github-secret", Namespace: configNamespace}},
+		},
+		{
+			name: "other namespace",
+			obj:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ldap-bind", Namespace: "default"}},
+		},
+		{
+			name: "unreferenced secret",
+			obj:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: configNamespace}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, reconciler.referencingOAuths(context.TODO(), tc.obj))
+		})
+	}
+}
+
+func TestParseHTPasswd(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     string
+		expected HTPasswdInventory
+	}{
+		{
+			name:     "empty",
+			expected: HTPasswdInventory{WeakHashes: map[string]int{}},
+		},
+		{
+			name: "bcrypt",
+			data: "alice:$2y$05$abcdefghijklmnopqrstuv\nbob:$2a$10$abcdefghijklmnopqrstuv\r\n\ncarol:$2b$10$abcdefghijklmnopqrstuv\n",
+			expected: HTPasswdInventory{
+				Users:      3,
+				WeakHashes: map[string]int{},
+			},
+		},
+		{
+			name: "weak hashes",
+			data: "alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nbob:$apr1$salt$abcdefghijklmnopqrstuv\ncarol:abJnggxhB/yWI\ndave:password\neve:$2y$05$abcdefghijklmnopqrstuv",
+			expected: HTPasswdInventory{
+				Users:      5,
+				WeakHashes: map[string]int{hashSHA1: 1, hashMD5: 1, hashCrypt: 1, hashUnknown: 1},
+			},
+		},
+		{
+			name: "malformed lines",
+			data: "alice\n:$2y$05$abcdefghijklmnopqrstuv\nbob:\ncarol:$2y$05$abcdefghijklmnopqrstuv",
+			expected: HTPasswdInventory{
+				Users:      1,
+				WeakHashes: map[string]int{},
+				Malformed:  3,
+			},
+		},
+		{
+			name: "duplicate user keeps the last hash",
+			data: "alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nalice:$2y$05$abcdefghijklmnopqrstuv",
+			expected: HTPasswdInventory{
+				Users:      1,
+				WeakHashes: map[string]int{},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, parseHTPasswd([]byte(tc.data)))
+		})
+	}
+}
+
+func TestReconcileOAuthHTPasswd_Reconcile(t *testing.T) {
+	err := configv1.Install(scheme.Scheme)
+	require.NoError(t, err)
+
+	htpasswdIDP := func(name, secret string) configv1.IdentityProvider {
+		return configv1.IdentityProvider{
+			Name: name,
+			IdentityProviderConfig: configv1.IdentityProviderConfig{
+				Type:     configv1.IdentityProviderTypeHTPasswd,
+				HTPasswd: &configv1.HTPasswdIdentityProvider{FileData: configv1.SecretNameReference{Name: secret}},
+			},
+		}
+	}
+	oauth := &configv1.OAuth{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.OAuthSpec{
+			IdentityProviders: []configv1.IdentityProvider{
+				htpasswdIDP("local", "htpasswd"),
+				htpasswdIDP("missing", "missing"),
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		oauth,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "htpasswd", Namespace: configNamespace},
+			Data: map[string][]byte{
+				htpasswdKey: []byte("admin:$2y$05$abcdefghijklmnopqrstuv\ndeveloper:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nbroken\n"),
+			},
+		},
+	).Build()
+	collector := NewCollector()
+	reconciler := OAuthReconciler{
+		Client:  fakeClient,
+		Metrics: collector,
+	}
+
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}})
+	require.NoError(t, err)
+	expected := `
+# HELP identity_provider_htpasswd_malformed_lines Number of lines of the htpasswd file of an HTPasswd identity provider which are not a user and a hash
+# TYPE identity_provider_htpasswd_malformed_lines gauge
+identity_provider_htpasswd_malformed_lines{idp="local",name="osd_exporter"} 1
+# HELP identity_provider_htpasswd_users Number of users of an HTPasswd identity provider
+# TYPE identity_provider_htpasswd_users gauge
+identity_provider_htpasswd_users{idp="local",name="osd_exporter"} 2
+# HELP identity_provider_htpasswd_weak_hashes Number of users of an HTPasswd identity provider whose password is hashed with a scheme other than bcrypt
+# TYPE identity_provider_htpasswd_weak_hashes gauge
+identity_provider_htpasswd_weak_hashes{idp="local",name="osd_exporter",scheme="crypt"} 0
+identity_provider_htpasswd_weak_hashes{idp="local",name="osd_exporter",scheme="md5"} 0
+identity_provider_htpasswd_weak_hashes{idp="local",name="osd_exporter",scheme="sha1"} 1
+identity_provider_htpasswd_weak_hashes{idp="local",name="osd_exporter",scheme="unknown"} 0
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"identity_provider_htpasswd_users", "identity_provider_htpasswd_weak_hashes", "identity_provider_htpasswd_malformed_lines")
+	require.NoError(t, err)
+
+	collector.DeleteOAuthIDP("cluster", "")
+	err = testutil.CollectAndCompare(collector, strings.NewReader(""),
+		"identity_provider_htpasswd_users", "identity_provider_htpasswd_weak_hashes", "identity_provider_htpasswd_malformed_lines")
+	require.NoError(t, err)
+}
+
+// TestKnownIdentityProviderTypes fails when the openshift/api dependency declares an identity provider type missing
+// from knownIdentityProviderTypes, so that providers of new types are not only counted as other
+func TestKnownIdentityProviderTypes(t *testing.T) {
+	pkg, err := build.Import("github.com/openshift/api/config/v1", ".", build.FindOnly)
+	require.NoError(t, err)
+	files, err := parser.ParseDir(token.NewFileSet(), pkg.Dir, nil, 0)
+	require.NoError(t, err)
+
+	var declared []configv1.IdentityProviderType
+	for _, file := range files["v1"].Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			spec, ok := node.(*ast.ValueSpec)
+			if !ok {
+				return true
 			}
+			if typ, ok := spec.Type.(*ast.Ident); !ok || typ.Name != "IdentityProviderType" {
+				return true
+			}
+			for _, value := range spec.Values {
+				literal, ok := value.(*ast.BasicLit)
+				require.True(t, ok, "IdentityProviderType constant is not a literal")
+				providerType, err := strconv.Unquote(literal.Value)
+				require.NoError(t, err)
+				declared = append(declared, configv1.IdentityProviderType(providerType))
+			}
+			return true
 		})
 	}
+	require.NotEmpty(t, declared)
+	require.ElementsMatch(t, declared, knownIdentityProviderTypes)
+}
+
+func TestNewSessionPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		spec     configv1.OAuthSpec
+		expected sessionPolicy
+	}{
+		{
+			name: "defaults",
+			expected: sessionPolicy{
+				accessTokenMaxAge: 24 * time.Hour,
+				templates:         map[string]bool{templateLogin: false, templateProviderSelection: false, templateError: false},
+			},
+		},
+		{
+			name: "customized",
+			spec: configv1.OAuthSpec{
+				TokenConfig: configv1.TokenConfig{
+					AccessTokenMaxAgeSeconds:     3600,
+					AccessTokenInactivityTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+				Templates: configv1.OAuthTemplates{
+					Login: configv1.SecretNameReference{Name: "login-template"},
+					Error: configv1.SecretNameReference{Name: "error-template"},
+				},
+			},
+			expected: sessionPolicy{
+				accessTokenMaxAge:            time.Hour,
+				accessTokenInactivityTimeout: 10 * time.Minute,
+				templates:                    map[string]bool{templateLogin: true, templateProviderSelection: false, templateError: true},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, newSessionPolicy(tc.spec))
+		})
+	}
+}
+
+func TestReconcileOAuthSessionPolicy_Reconcile(t *testing.T) {
+	err := configv1.Install(scheme.Scheme)
+	require.NoError(t, err)
+
+	oauth := &configv1.OAuth{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.OAuthSpec{
+			TokenConfig: configv1.TokenConfig{
+				AccessTokenMaxAgeSeconds:     172800,
+				AccessTokenInactivityTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			Templates: configv1.OAuthTemplates{
+				ProviderSelection: configv1.SecretNameReference{Name: "providers-template"},
+			},
+		},
+	}
+	collector := NewCollector()
+	reconciler := OAuthReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(oauth).Build(),
+		Metrics: collector,
+	}
+
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster"}})
+	require.NoError(t, err)
+	expected := `
+# HELP oauth_access_token_inactivity_timeout_seconds Inactivity timeout of the access tokens granted by any client, 0 if tokens are valid until their lifetime
+# TYPE oauth_access_token_inactivity_timeout_seconds gauge
+oauth_access_token_inactivity_timeout_seconds{name="osd_exporter"} 300
+# HELP oauth_access_token_max_age_seconds Maximum age of the access tokens granted by the OAuth server
+# TYPE oauth_access_token_max_age_seconds gauge
+oauth_access_token_max_age_seconds{name="osd_exporter"} 172800
+# HELP oauth_template_customized Indicates if a page of the OAuth server is rendered from a custom template
+# TYPE oauth_template_customized gauge
+oauth_template_customized{name="osd_exporter",template="error"} 0
+oauth_template_customized{name="osd_exporter",template="login"} 0
+oauth_template_customized{name="osd_exporter",template="provider_selection"} 1
+`
+	policyMetrics := []string{
+		"oauth_access_token_max_age_seconds", "oauth_access_token_inactivity_timeout_seconds", "oauth_template_customized",
+	}
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected), policyMetrics...)
+	require.NoError(t, err)
+
+	collector.DeleteOAuthIDP("cluster", "")
+	err = testutil.CollectAndCompare(collector, strings.NewReader(""), policyMetrics...)
+	require.NoError(t, err)
+}
+
+func TestCollector_SeveralOAuths(t *testing.T) {
+	collector := NewCollector()
+	githubIDP := func(organization string) []configv1.IdentityProvider {
+		return []configv1.IdentityProvider{{
+			Name: "github",
+			IdentityProviderConfig: configv1.IdentityProviderConfig{
+				Type:   configv1.IdentityProviderTypeGitHub,
+				GitHub: &configv1.GitHubIdentityProvider{Organizations: []string{organization}},
+			},
+		}}
+	}
+	// Unrestricted identity provider of another OAuth resource, sorting before the cluster one
+	collector.SetOAuthIDP("another", "", []configv1.IdentityProvider{{
+		Name: "github",
+		IdentityProviderConfig: configv1.IdentityProviderConfig{
+			Type:   configv1.IdentityProviderTypeGitHub,
+			GitHub: &configv1.GitHubIdentityProvider{},
+		},
+	}})
+	collector.SetOAuthSessionPolicy("another", "", configv1.OAuthSpec{
+		TokenConfig: configv1.TokenConfig{AccessTokenMaxAgeSeconds: 60},
+	})
+	collector.SetOAuthIDP(clusterOAuthName, "", githubIDP("org"))
+	collector.SetOAuthSessionPolicy(clusterOAuthName, "", configv1.OAuthSpec{
+		TokenConfig: configv1.TokenConfig{AccessTokenMaxAgeSeconds: 3600},
+	})
+
+	// The cluster OAuth resource is reported on every scrape, regardless of the order of the map
+	expected := `
+# HELP identity_provider_info Configuration of an identity provider, with flags of risky settings
+# TYPE identity_provider_info gauge
+identity_provider_info{idp="github",insecure="false",mapping_method="claim",missing_ca="false",name="osd_exporter",type="GitHub",unrestricted="false"} 1
+# HELP oauth_access_token_max_age_seconds Maximum age of the access tokens granted by the OAuth server
+# TYPE oauth_access_token_max_age_seconds gauge
+oauth_access_token_max_age_seconds{name="osd_exporter"} 3600
+`
+	for i := 0; i < 10; i++ {
+		err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+			"identity_provider_info", "oauth_access_token_max_age_seconds")
+		require.NoError(t, err)
+	}
+
+	// Without the cluster OAuth resource, the token config is not reported
+	collector.DeleteOAuthIDP(clusterOAuthName, "")
+	require.Equal(t, 0, testutil.CollectAndCount(collector, "oauth_access_token_max_age_seconds"))
+	require.Equal(t, 1, testutil.CollectAndCount(collector, "identity_provider_info"))
+}
+
+func TestOrderedKeys(t *testing.T) {
+	keys := orderedKeys(map[providerKey]bool{
+		{name: "b"}:                  true,
+		{name: "a", namespace: "ns"}: true,
+		clusterOAuthKey:              true,
+		{name: "a"}:                  true,
+	})
+	require.Equal(t, []providerKey{clusterOAuthKey, {name: "a"}, {name: "b"}, {name: "a", namespace: "ns"}}, keys)
 }
@@ -0,0 +1,58 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauth
+
+import (
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// defaultAccessTokenMaxAge is the lifetime of access tokens when the token config does not set one
+const defaultAccessTokenMaxAge = 24 * time.Hour
+
+// Templates of the pages rendered by the OAuth server
+const (
+	templateLogin             = "login"
+	templateProviderSelection = "provider_selection"
+	templateError             = "error"
+)
+
+// sessionPolicy is what is reported on the token config and templates of an OAuth resource
+type sessionPolicy struct {
+	accessTokenMaxAge time.Duration
+	// accessTokenInactivityTimeout is zero if tokens are valid until their lifetime
+	accessTokenInactivityTimeout time.Duration
+	// templates is whether each template is customized
+	templates map[string]bool
+}
+
+// newSessionPolicy returns what is reported on spec. The access token lifetime defaults to 24 hours as in the API.
+func newSessionPolicy(spec configv1.OAuthSpec) sessionPolicy {
+	policy := sessionPolicy{
+		accessTokenMaxAge: time.Duration(spec.TokenConfig.AccessTokenMaxAgeSeconds) * time.Second,
+		templates: map[string]bool{
+			templateLogin:             spec.Templates.Login.Name != "",
+			templateProviderSelection: spec.Templates.ProviderSelection.Name != "",
+			templateError:             spec.Templates.Error.Name != "",
+		},
+	}
+	if policy.accessTokenMaxAge == 0 {
+		policy.accessTokenMaxAge = defaultAccessTokenMaxAge
+	}
+	if timeout := spec.TokenConfig.AccessTokenInactivityTimeout; timeout != nil {
+		policy.accessTokenInactivityTimeout = timeout.Duration
+	}
+	return policy
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauth
+
+import (
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// identityProvider is what is reported on a single identity provider of an OAuth resource
+type identityProvider struct {
+	name          string
+	providerType  configv1.IdentityProviderType
+	mappingMethod configv1.MappingMethodType
+	// insecure is set for LDAP providers without TLS, i.e. insecure or not using an ldaps:// URL
+	insecure bool
+	// missingCA is set for OpenID providers without CA, and RequestHeader providers without client CA
+	missingCA bool
+	// unrestricted is set for GitHub providers without organization or team restrictions, and GitLab
+	// providers which cannot be restricted
+	unrestricted bool
+}
+
+// newIdentityProvider returns what is reported on idp. The mapping method defaults to claim as in the API.
+func newIdentityProvider(idp configv1.IdentityProvider) identityProvider {
+	provider := identityProvider{
+		name:          idp.Name,
+		providerType:  idp.Type,
+		mappingMethod: idp.MappingMethod,
+	}
+	if provider.mappingMethod == "" {
+		provider.mappingMethod = configv1.MappingMethodClaim
+	}
+	switch idp.Type {
+	case configv1.IdentityProviderTypeLDAP:
+		if ldap := idp.LDAP; ldap != nil {
+			provider.insecure = ldap.Insecure || !strings.HasPrefix(strings.ToLower(ldap.URL), "ldaps://")
+		}
+	case configv1.IdentityProviderTypeOpenID:
+		if openID := idp.OpenID; openID != nil {
+			provider.missingCA = openID.CA.Name == ""
+		}
+	case configv1.IdentityProviderTypeRequestHeader:
+		if requestHeader := idp.RequestHeader; requestHeader != nil {
+			provider.missingCA = requestHeader.ClientCA.Name == ""
+		}
+	case configv1.IdentityProviderTypeGitHub:
+		if gitHub := idp.GitHub; gitHub != nil {
+			provider.unrestricted = len(gitHub.Organizations) == 0 && len(gitHub.Teams) == 0
+		}
+	case configv1.IdentityProviderTypeGitLab:
+		provider.unrestricted = true
+	}
+	return provider
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauth
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// configNamespace holds the ConfigMaps and Secrets referenced from identity providers
+const configNamespace = "openshift-config"
+
+// Fields of identity providers referencing a ConfigMap or Secret
+const (
+	fieldClientSecret  = "clientSecret"
+	fieldCA            = "ca"
+	fieldClientCA      = "clientCA"
+	fieldBindPassword  = "bindPassword"
+	fieldFileData      = "fileData"
+	fieldTLSClientCert = "tlsClientCert"
+	fieldTLSClientKey  = "tlsClientKey"
+)
+
+// Keys the identity providers read from the referenced objects
+const (
+	caKey           = "ca.crt"
+	clientSecretKey = "clientSecret"
+	bindPasswordKey = "bindPassword" // #nosec G101 -- this is a key name, not a credential
+	htpasswdKey     = "htpasswd"
+)
+
+// ReferenceStatus is whether a reference of an identity provider resolves
+type ReferenceStatus struct {
+	IDP string
+	// Field is the referencing field of the identity provider, e.g. "clientSecret"
+	Field string
+	// Valid is true if the referenced object exists in openshift-config and holds the key read by the provider
+	Valid bool
+}
+
+// reference is a reference of an identity provider to a key of a ConfigMap or Secret of openshift-config
+type reference struct {
+	field     string
+	configMap bool
+	name      string
+	key       string
+}
+
+// matches returns whether ref references obj
+func (ref reference) matches(obj client.Object) bool {
+	if obj.GetNamespace() != configNamespace || obj.GetName() != ref.name {
+		return false
+	}
+	_, isConfigMap := obj.(*corev1.ConfigMap)
+	return isConfigMap == ref.configMap
+}
+
+// identityProviderReferences returns the set references of idp
+func identityProviderReferences(idp configv1.IdentityProviderConfig) []reference {
+	var references []reference
+	configMap := func(field string, ref configv1.ConfigMapNameReference) {
+		if ref.Name != "" {
+			references = append(references, reference{field: field, configMap: true, name: ref.Name, key: caKey})
+		}
+	}
+	secret := func(field string, ref configv1.SecretNameReference, key string) {
+		if ref.Name != "" {
+			references = append(references, reference{field: field, name: ref.Name, key: key})
+		}
+	}
+	if p := idp.BasicAuth; p != nil {
+		configMap(fieldCA, p.CA)
+		secret(fieldTLSClientCert, p.TLSClientCert, corev1.TLSCertKey)
+		secret(fieldTLSClientKey, p.TLSClientKey, corev1.TLSPrivateKeyKey)
+	}
+	if p := idp.GitHub; p != nil {
+		secret(fieldClientSecret, p.ClientSecret, clientSecretKey)
+		configMap(fieldCA, p.CA)
+	}
+	if p := idp.GitLab; p != nil {
+		secret(fieldClientSecret, p.ClientSecret, clientSecretKey)
+		configMap(fieldCA, p.CA)
+	}
+	if p := idp.Google; p != nil {
+		secret(fieldClientSecret, p.ClientSecret, clientSecretKey)
+	}
+	if p := idp.HTPasswd; p != nil {
+		secret(fieldFileData, p.FileData, htpasswdKey)
+	}
+	if p := idp.Keystone; p != nil {
+		configMap(fieldCA, p.CA)
+		secret(fieldTLSClientCert, p.TLSClientCert, corev1.TLSCertKey)
+		secret(fieldTLSClientKey, p.TLSClientKey, corev1.TLSPrivateKeyKey)
+	}
+	if p := idp.LDAP; p != nil {
+		secret(fieldBindPassword, p.BindPassword, bindPasswordKey)
+		configMap(fieldCA, p.CA)
+	}
+	if p := idp.OpenID; p != nil {
+		secret(fieldClientSecret, p.ClientSecret, clientSecretKey)
+		configMap(fieldCA, p.CA)
+	}
+	if p := idp.RequestHeader; p != nil {
+		configMap(fieldClientCA, p.ClientCA)
+	}
+	return references
+}
+
+// resolveReferences resolves every reference of the identity providers
+func (r *OAuthReconciler) resolveReferences(ctx context.Context, idps []configv1.IdentityProvider) ([]ReferenceStatus, error) {
+	var statuses []ReferenceStatus
+	for _, idp := range idps {
+		for _, ref := range identityProviderReferences(idp.IdentityProviderConfig) {
+			valid, err := r.resolveReference(ctx, ref)
+			if err != nil {
+				return nil, err
+			}
+			if !valid {
+				log.Info("Identity provider references a missing object or key", "idp", idp.Name, "field", ref.field,
+					"configMap", ref.configMap, "object", ref.name, "key", ref.key)
+			}
+			statuses = append(statuses, ReferenceStatus{IDP: idp.Name, Field: ref.field, Valid: valid})
+		}
+	}
+	return statuses, nil
+}
+
+// resolveReference returns whether the object of ref exists and holds a non-empty key
+func (r *OAuthReconciler) resolveReference(ctx context.Context, ref reference) (bool, error) {
+	key := types.NamespacedName{Namespace: configNamespace, Name: ref.name}
+	if ref.configMap {
+		configMap := &corev1.ConfigMap{}
+		if err := r.Get(ctx, key, configMap); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return configMap.Data[ref.key] != "" || len(configMap.BinaryData[ref.key]) > 0, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(secret.Data[ref.key]) > 0, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"sync"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName   = "proxy"
+	proxyHTTPLabel  = "http"
+	proxyHTTPSLabel = "https"
+	proxyCALabel    = "trusted_ca"
+)
+
+var (
+	clusterProxyDesc = metrics.NewDesc(
+		"cluster_proxy",
+		"Indicates cluster proxy state",
+		metrics.ClusterIDLabel, proxyHTTPLabel, proxyHTTPSLabel, proxyCALabel,
+	)
+	clusterIDDesc = metrics.NewDesc(
+		"cluster_id",
+		"Indicates the cluster id",
+		metrics.ClusterIDLabel,
+	)
+)
+
+type clusterProxy struct {
+	clusterId string
+	http      string
+	https     string
+	trustedCA string
+	enabled   int
+}
+
+// Collector exposes the cluster id and the cluster wide proxy configuration
+type Collector struct {
+	metrics.ReconcileStatus
+
+	// clusterId is empty until the cluster id has been set
+	clusterId string
+	// clusterProxy is nil until the proxy configuration has been read
+	clusterProxy *clusterProxy
+	mutex        sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterProxyDesc
+	ch <- clusterIDDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if p := c.clusterProxy; p != nil {
+		ch <- metrics.NewGauge(clusterProxyDesc, float64(p.enabled), p.clusterId, p.http, p.https, p.trustedCA)
+	}
+	if c.clusterId != "" {
+		ch <- metrics.NewGauge(clusterIDDesc, 1, c.clusterId)
+	}
+}
+
+func (c *Collector) SetClusterProxy(uuid string, proxyHTTP string, proxyHTTPS string, proxyTrustedCA string, proxyEnabled int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterProxy = &clusterProxy{
+		clusterId: uuid,
+		http:      proxyHTTP,
+		https:     proxyHTTPS,
+		trustedCA: proxyTrustedCA,
+		enabled:   proxyEnabled,
+	}
+}
+
+func (c *Collector) SetClusterID(uuid string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterId = uuid
+}
+
+// Reset forgets everything reported on the cluster id and the proxy configuration
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterId = ""
+	c.clusterProxy = nil
+}
@@ -19,6 +19,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,12 +28,15 @@ import (
 
 var log = logf.Log.WithName("controller_proxy")
 
+// clusterProxyName is the name of the cluster wide proxy configuration
+const clusterProxyName = "cluster"
+
 // ProxyReconciler reconciles a Proxy object
 type ProxyReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
 }
 
 // Reconcile reads that state of the cluster for a Proxy object and makes changes based on the state read
@@ -45,7 +49,7 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	reqLogger.Info("Reconciling Proxy")
 
-	r.MetricsAggregator.SetClusterID(r.ClusterId)
+	r.Metrics.SetClusterID(r.ClusterId)
 
 	// Fetch the Proxy instance
 	instance := &configv1.Proxy{}
@@ -80,7 +84,7 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		proxyTrustedCA = "1"
 	}
 	// aggregate metrics
-	r.MetricsAggregator.SetClusterProxy(r.ClusterId, proxyHTTP, proxyHTTPS, proxyTrustedCA, proxyEnabled)
+	r.Metrics.SetClusterProxy(r.ClusterId, proxyHTTP, proxyHTTPS, proxyTrustedCA, proxyEnabled)
 	return ctrl.Result{}, nil
 }
 
@@ -88,5 +92,8 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 func (r *ProxyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&configv1.Proxy{}).
-		Complete(r)
+		WatchesRawSource(r.Metrics.ResyncSource(&configv1.Proxy{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterProxyName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
@@ -17,12 +17,10 @@ import (
 	"context"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	configv1 "github.com/openshift/api/config/v1"
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -95,17 +93,15 @@ cluster_proxy{_id="cluster-id",http="1",https="1",name="osd_exporter",trusted_ca
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, "cluster-id")
-			done := metricsAggregator.Run()
-			defer close(done)
+			collector := NewCollector()
 			err := configv1.Install(scheme.Scheme)
 			require.NoError(t, err)
 
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(makeTestProxy(testName, testNamespace, tc.proxySpec, tc.proxyStatus)).Build()
 			reconciler := ProxyReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         "cluster-id",
+				Client:    fakeClient,
+				Metrics:   collector,
+				ClusterId: "cluster-id",
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -115,20 +111,16 @@ cluster_proxy{_id="cluster-id",http="1",https="1",name="osd_exporter",trusted_ca
 			})
 			require.NoError(t, err)
 
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testProxy configv1.Proxy
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: testName, Namespace: testNamespace}, &testProxy)
 			require.NoError(t, err)
 
-			metric := metricsAggregator.GetClusterIDMetric()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedClusterIDResults))
+			err = testutil.CollectAndCompare(collector, strings.NewReader(tc.expectedClusterIDResults), "cluster_id")
 			require.NoError(t, err)
 
-			metric = metricsAggregator.GetClusterProxyMetric()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedProxyResults))
+			err = testutil.CollectAndCompare(collector, strings.NewReader(tc.expectedProxyResults), "cluster_proxy")
 			require.NoError(t, err)
 		})
 	}
@@ -0,0 +1,298 @@
+/*
+Copyright 2025.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorName         = "pullsecret"
+	pullSecretValidMetric = "pull_secret_valid"
+	pullSecretReasonLabel = "reason"
+	registryLabel         = "registry"
+	requiredLabel         = "required"
+)
+
+var (
+	pullSecretValidDesc = metrics.NewDesc(
+		pullSecretValidMetric,
+		"Indicates if the cluster pull secret is valid (1=valid, 0=invalid)",
+		metrics.ClusterIDLabel, pullSecretReasonLabel,
+	)
+	pullSecretValidTransitionDesc = metrics.NewTransitionDesc(pullSecretValidMetric, metrics.ClusterIDLabel)
+	pullSecretRegistryPresentDesc = metrics.NewDesc(
+		"pull_secret_registry_present",
+		"Indicates if the cluster pull secret holds an entry for a registry pattern of the registry policy",
+		metrics.ClusterIDLabel, registryLabel, requiredLabel,
+	)
+	pullSecretInvalidEntriesDesc = metrics.NewDesc(
+		"pull_secret_invalid_entries",
+		"Number of entries of the cluster pull secret holding an invalid credential, by reason",
+		metrics.ClusterIDLabel, pullSecretReasonLabel,
+	)
+	pullSecretRegistryAuthOKDesc = metrics.NewDesc(
+		"pull_secret_registry_auth_ok",
+		"Indicates if a registry accepted the credential of the cluster pull secret during the last conclusive probe",
+		metrics.ClusterIDLabel, registryLabel,
+	)
+	pullSecretRegistryLastProbeDesc = metrics.NewDesc(
+		"pull_secret_registry_last_probe_timestamp_seconds",
+		"Unix timestamp of the last conclusive probe of the credential of a registry of the cluster pull secret",
+		metrics.ClusterIDLabel, registryLabel,
+	)
+	pullSecretUnknownFieldsDesc = metrics.NewDesc(
+		"pull_secret_unknown_fields",
+		"Number of fields of the cluster pull secret and its entries not understood by the container runtimes",
+		metrics.ClusterIDLabel,
+	)
+	pullSecretRegistriesDesc = metrics.NewDesc(
+		"pull_secret_registries",
+		"Number of registry entries of the cluster pull secret",
+		metrics.ClusterIDLabel,
+	)
+	pullSecretLastChangeDesc = metrics.NewDesc(
+		"pull_secret_last_change_timestamp_seconds",
+		"Unix timestamp of the last change of the entries of the cluster pull secret",
+		metrics.ClusterIDLabel,
+	)
+	pullSecretRotationsDesc = metrics.NewDesc(
+		"pull_secret_rotations_total",
+		"Number of changes of the entries of the cluster pull secret observed since the exporter started",
+		metrics.ClusterIDLabel,
+	)
+)
+
+type pullSecretValidity struct {
+	clusterId string
+	valid     bool
+	reason    string
+	// lastConfirmed is the last time the reconciler validated the pull secret
+	lastConfirmed time.Time
+}
+
+// Entries is what is reported on the entries of the pull secret
+type Entries struct {
+	Registries []RegistryPresence
+	// InvalidEntries is the number of entries holding an invalid credential by reason
+	InvalidEntries map[string]int
+	// UnknownFields are the paths of the fields not understood by the container runtimes
+	UnknownFields []string
+}
+
+type pullSecretEntries struct {
+	clusterId string
+	entries   Entries
+	// lastConfirmed is the last time the reconciler read the entries of the pull secret
+	lastConfirmed time.Time
+}
+
+// Changes is what is reported on the changes of the entries of the pull secret
+type Changes struct {
+	// Registries is the number of entries
+	Registries int
+	// LastChange is when the entries last changed, the last update of the pull secret until a change is observed
+	LastChange time.Time
+	// Rotations is the number of changes of the entries observed since the exporter started
+	Rotations int
+}
+
+type pullSecretChanges struct {
+	clusterId string
+	changes   Changes
+	// lastConfirmed is the last time the reconciler fingerprinted the entries of the pull secret
+	lastConfirmed time.Time
+}
+
+type registryProbes struct {
+	clusterId string
+	results   []ProbeResult
+	// lastConfirmed is the last time the reconciler reported the probe results
+	lastConfirmed time.Time
+}
+
+// Collector exposes the validity of the cluster pull secret
+type Collector struct {
+	metrics.ReconcileStatus
+
+	// validity is nil until the pull secret has been validated
+	validity *pullSecretValidity
+	// valid tracks the transitions of validity.valid, a change of the reason alone is no transition
+	valid metrics.BoolTransition
+	// entries is nil while the entries of the pull secret cannot be read
+	entries *pullSecretEntries
+	// probes is nil while probing is disabled
+	probes *registryProbes
+	// changes is nil while the entries of the pull secret cannot be fingerprinted
+	changes *pullSecretChanges
+	mutex   sync.Mutex
+}
+
+var (
+	_ metrics.Collector = &Collector{}
+	_ metrics.Sweeper   = &Collector{}
+	_ metrics.Resetter  = &Collector{}
+)
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collectorName
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pullSecretValidDesc
+	ch <- pullSecretValidTransitionDesc
+	ch <- pullSecretRegistryPresentDesc
+	ch <- pullSecretInvalidEntriesDesc
+	ch <- pullSecretUnknownFieldsDesc
+	ch <- pullSecretRegistryAuthOKDesc
+	ch <- pullSecretRegistryLastProbeDesc
+	ch <- pullSecretRegistriesDesc
+	ch <- pullSecretLastChangeDesc
+	ch <- pullSecretRotationsDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if v := c.validity; v != nil {
+		ch <- metrics.NewGauge(pullSecretValidDesc, metrics.BoolToFloat64(v.valid), v.clusterId, v.reason)
+		c.valid.Collect(ch, pullSecretValidTransitionDesc, v.clusterId)
+	}
+	if e := c.entries; e != nil {
+		for _, r := range e.entries.Registries {
+			ch <- metrics.NewGauge(pullSecretRegistryPresentDesc, metrics.BoolToFloat64(r.Present),
+				e.clusterId, r.Registry, strconv.FormatBool(r.Required))
+		}
+		for _, reason := range entryReasons {
+			ch <- metrics.NewGauge(pullSecretInvalidEntriesDesc, float64(e.entries.InvalidEntries[reason]), e.clusterId, reason)
+		}
+		ch <- metrics.NewGauge(pullSecretUnknownFieldsDesc, float64(len(e.entries.UnknownFields)), e.clusterId)
+	}
+	if p := c.probes; p != nil {
+		for _, r := range p.results {
+			ch <- metrics.NewGauge(pullSecretRegistryAuthOKDesc, metrics.BoolToFloat64(r.OK), p.clusterId, r.Registry)
+			ch <- metrics.NewGauge(pullSecretRegistryLastProbeDesc, float64(r.LastProbe.Unix()), p.clusterId, r.Registry)
+		}
+	}
+	if changes := c.changes; changes != nil {
+		ch <- metrics.NewGauge(pullSecretRegistriesDesc, float64(changes.changes.Registries), changes.clusterId)
+		ch <- metrics.NewGauge(pullSecretLastChangeDesc, float64(changes.changes.LastChange.Unix()), changes.clusterId)
+		ch <- metrics.NewCounter(pullSecretRotationsDesc, float64(changes.changes.Rotations), changes.clusterId)
+	}
+}
+
+func (c *Collector) SetPullSecretValid(uuid string, valid bool, reason string) {
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.validity = &pullSecretValidity{
+		clusterId:     uuid,
+		valid:         valid,
+		reason:        reason,
+		lastConfirmed: now,
+	}
+	c.valid.Set(valid, now)
+}
+
+// SetEntries replaces what is reported on the entries of the pull secret, nil stops reporting on the entries
+func (c *Collector) SetEntries(uuid string, entries *Entries) {
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entries == nil {
+		c.entries = nil
+		return
+	}
+	c.entries = &pullSecretEntries{
+		clusterId:     uuid,
+		entries:       *entries,
+		lastConfirmed: now,
+	}
+}
+
+// SetProbeResults replaces the reported results of the credential probes, nil stops reporting on the probes
+func (c *Collector) SetProbeResults(uuid string, results []ProbeResult) {
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if results == nil {
+		c.probes = nil
+		return
+	}
+	c.probes = &registryProbes{
+		clusterId:     uuid,
+		results:       results,
+		lastConfirmed: now,
+	}
+}
+
+// SetChanges replaces what is reported on the changes of the entries of the pull secret, nil stops
+// reporting on the changes
+func (c *Collector) SetChanges(uuid string, changes *Changes) {
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if changes == nil {
+		c.changes = nil
+		return
+	}
+	c.changes = &pullSecretChanges{
+		clusterId:     uuid,
+		changes:       *changes,
+		lastConfirmed: now,
+	}
+}
+
+// Sweep stops reporting the pull secret validity if it has not been confirmed since expiredBefore
+func (c *Collector) Sweep(expiredBefore time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.validity != nil && c.validity.lastConfirmed.Before(expiredBefore) {
+		log.Info("Dropping stale pull secret metrics", "reason", c.validity.reason, "lastConfirmed", c.validity.lastConfirmed)
+		c.validity = nil
+		c.valid.Reset()
+	}
+	if c.entries != nil && c.entries.lastConfirmed.Before(expiredBefore) {
+		log.Info("Dropping stale pull secret entry metrics", "lastConfirmed", c.entries.lastConfirmed)
+		c.entries = nil
+	}
+	if c.probes != nil && c.probes.lastConfirmed.Before(expiredBefore) {
+		log.Info("Dropping stale pull secret probe metrics", "lastConfirmed", c.probes.lastConfirmed)
+		c.probes = nil
+	}
+	if c.changes != nil && c.changes.lastConfirmed.Before(expiredBefore) {
+		log.Info("Dropping stale pull secret change metrics", "lastConfirmed", c.changes.lastConfirmed)
+		c.changes = nil
+	}
+}
+
+// Reset forgets everything reported on the pull secret
+func (c *Collector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.validity = nil
+	c.valid.Reset()
+	c.entries = nil
+	c.probes = nil
+	c.changes = nil
+}
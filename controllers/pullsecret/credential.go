@@ -0,0 +1,96 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const (
+	// Reason labels of entries holding an invalid credential
+	ReasonInvalidBase64       = "InvalidBase64"
+	ReasonMalformedCredential = "MalformedCredential" // #nosec G101 -- this is a metric label, not a credential
+	ReasonEmptyUsername       = "EmptyUsername"
+	ReasonEmptyPassword       = "EmptyPassword"
+	ReasonCredentialMismatch  = "CredentialMismatch" // #nosec G101 -- this is a metric label, not a credential
+)
+
+// entryReasons are the reasons entries of the pull secret are rejected for, in the order they are checked
+var entryReasons = []string{
+	ReasonInvalidBase64,
+	ReasonMalformedCredential,
+	ReasonEmptyUsername,
+	ReasonEmptyPassword,
+	ReasonCredentialMismatch,
+}
+
+// knownFields are the fields of the pull secret and of its entries understood by the container runtimes
+var (
+	knownFields      = map[string]bool{"auths": true}
+	knownEntryFields = map[string]bool{"auth": true, "username": true, "password": true, "email": true, "identitytoken": true}
+)
+
+// decodeCredential returns the username and password of auth, or the reason its credential is invalid for.
+// The auth token has to be the base64 encoding of "user:password", agreeing with the separate username
+// and password fields if they are set. Credentials are never part of the reason.
+func decodeCredential(auth dockerConfigAuth) (string, string, string) {
+	decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+	if err != nil {
+		return "", "", ReasonInvalidBase64
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", ReasonMalformedCredential
+	}
+	if username == "" {
+		return "", "", ReasonEmptyUsername
+	}
+	if password == "" {
+		return "", "", ReasonEmptyPassword
+	}
+	if (auth.Username != "" && auth.Username != username) || (auth.Password != "" && auth.Password != password) {
+		return "", "", ReasonCredentialMismatch
+	}
+	return username, password, ""
+}
+
+// unknownFields returns the sorted paths of the fields of the dockerconfigjson data which are not
+// understood by the container runtimes, e.g. "auths.quay.io.token"
+func unknownFields(data []byte) []string {
+	var config struct {
+		Auths map[string]map[string]json.RawMessage `json:"auths"`
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil || json.Unmarshal(data, &config) != nil {
+		return nil
+	}
+	var unknown []string
+	for field := range fields {
+		if !knownFields[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	for registry, entry := range config.Auths {
+		for field := range entry {
+			if !knownEntryFields[field] {
+				unknown = append(unknown, "auths."+registry+"."+field)
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// pullSecretChangedEventReason is the reason of the events recorded on changes of the entries
+const pullSecretChangedEventReason = "PullSecretChanged"
+
+// entryChange is the difference between two observations of the entries of the pull secret
+type entryChange struct {
+	added   []string
+	removed []string
+	// rotated are the registries whose entry changed
+	rotated []string
+}
+
+func (c entryChange) empty() bool {
+	return len(c.added) == 0 && len(c.removed) == 0 && len(c.rotated) == 0
+}
+
+// changeTracker follows the entries of the pull secret between reconciles by their fingerprints. A
+// fingerprint is an HMAC-SHA256 of the entry keyed with a random key of the tracker, so that neither
+// the fingerprints nor the state of the tracker reveal the credentials, even weak ones. Fingerprints
+// are only comparable within the same tracker, i.e. the same exporter process.
+type changeTracker struct {
+	key []byte
+	// fingerprints of the entries by registry, nil until the entries have been observed
+	fingerprints map[string]string
+	lastChange   time.Time
+	rotations    int
+	mutex        sync.Mutex
+}
+
+// fingerprintEntries returns the fingerprint of every entry of the dockerconfigjson data, nil if
+// the data cannot be parsed. Entries are fingerprinted in their canonical JSON encoding, so that
+// formatting changes do not count as changes.
+func (t *changeTracker) fingerprintEntries(data []byte) map[string]string {
+	var config struct {
+		Auths map[string]map[string]interface{} `json:"auths"`
+	}
+	if json.Unmarshal(data, &config) != nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.key == nil {
+		t.key = make([]byte, sha256.Size)
+		if _, err := rand.Read(t.key); err != nil {
+			return nil
+		}
+	}
+	fingerprints := make(map[string]string, len(config.Auths))
+	for registry, entry := range config.Auths {
+		canonical, err := json.Marshal(entry)
+		if err != nil {
+			return nil
+		}
+		mac := hmac.New(sha256.New, t.key)
+		mac.Write([]byte(registry))
+		mac.Write([]byte{0})
+		mac.Write(canonical)
+		fingerprints[registry] = hex.EncodeToString(mac.Sum(nil))
+	}
+	return fingerprints
+}
+
+// observe records the fingerprints of the entries and returns what is reported on their changes,
+// together with the change since the previous observation. The first observation is no change, the
+// last update of secret is taken as the last change then.
+func (t *changeTracker) observe(secret *corev1.Secret, fingerprints map[string]string, now time.Time) (*Changes, entryChange) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var change entryChange
+	if t.fingerprints == nil {
+		t.lastChange = lastUpdate(secret)
+	} else {
+		change = diffFingerprints(t.fingerprints, fingerprints)
+		if !change.empty() {
+			t.lastChange = now
+			t.rotations++
+		}
+	}
+	t.fingerprints = fingerprints
+	return &Changes{
+		Registries: len(fingerprints),
+		LastChange: t.lastChange,
+		Rotations:  t.rotations,
+	}, change
+}
+
+// diffFingerprints returns the sorted registries added, removed and rotated from previous to current
+func diffFingerprints(previous, current map[string]string) entryChange {
+	var change entryChange
+	for registry, fingerprint := range current {
+		previousFingerprint, ok := previous[registry]
+		switch {
+		case !ok:
+			change.added = append(change.added, registry)
+		case previousFingerprint != fingerprint:
+			change.rotated = append(change.rotated, registry)
+		}
+	}
+	for registry := range previous {
+		if _, ok := current[registry]; !ok {
+			change.removed = append(change.removed, registry)
+		}
+	}
+	sort.Strings(change.added)
+	sort.Strings(change.removed)
+	sort.Strings(change.rotated)
+	return change
+}
+
+// lastUpdate returns the last time secret was written according to its managed fields, its creation
+// time if it has none
+func lastUpdate(secret *corev1.Secret) time.Time {
+	last := secret.CreationTimestamp.Time
+	for _, entry := range secret.ManagedFields {
+		if entry.Time != nil && entry.Time.After(last) {
+			last = entry.Time.Time
+		}
+	}
+	return last
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProbeInterval is how often every credential is probed unless configured otherwise
+	DefaultProbeInterval = time.Hour
+	// probeRetryInterval is the first retry delay of a probe failing to reach a conclusion,
+	// doubled on every further failure up to the probe interval
+	probeRetryInterval = time.Minute
+	probeTimeout       = 10 * time.Second
+)
+
+// dockerHubRegistries are the names of Docker Hub in pull secrets, which serves its v2 API elsewhere
+var dockerHubRegistries = map[string]bool{
+	"docker.io":       true,
+	"index.docker.io": true,
+}
+
+const (
+	dockerHubEndpoint = "https://registry-1.docker.io"
+	// dockerHubAuthHost serves the token endpoint of Docker Hub
+	dockerHubAuthHost = "auth.docker.io"
+)
+
+// credential is the decoded credential of a registry entry of the pull secret
+type credential struct {
+	registry string
+	username string
+	password string
+}
+
+// ProbeResult is the outcome of the last conclusive probe of the credential of a registry
+type ProbeResult struct {
+	Registry string
+	// OK is true if the registry accepted the credential
+	OK        bool
+	LastProbe time.Time
+}
+
+// probeState is what the prober keeps per registry between reconciles
+type probeState struct {
+	result    *ProbeResult
+	failures  int
+	nextProbe time.Time
+}
+
+// Prober checks the credentials of the pull secret against the Docker Registry v2 API: the /v2/ endpoint,
+// and the token endpoint it points to for registries using bearer tokens. Probes are spread out by the
+// probe interval, and probes failing to reach a conclusion are retried with an exponential backoff.
+type Prober struct {
+	Client *http.Client
+	// Endpoints overrides the base URL of registries, "https://<registry host>" otherwise
+	Endpoints map[string]string
+	// Timeout bounds every call of Probe, the probe timeout if zero
+	Timeout time.Duration
+
+	states map[string]*probeState
+	mutex  sync.Mutex
+}
+
+func NewProber() *Prober {
+	return &Prober{Client: &http.Client{Timeout: probeTimeout}}
+}
+
+// Probe probes every credential whose probe is due and returns the results of the last conclusive
+// probe of every credential. Registries which are no longer part of credentials are forgotten.
+// Due credentials are probed concurrently and the whole call is bounded by the timeout, so a
+// slow registry neither stalls the caller nor delays the probes of other registries.
+func (p *Prober) Probe(ctx context.Context, credentials []credential, interval time.Duration) []ProbeResult {
+	now := time.Now()
+	due := p.dueCredentials(credentials, now, interval)
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = probeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	outcomes := make([]probeOutcome, len(due))
+	var wg sync.WaitGroup
+	for i, cred := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcomes[i].ok, outcomes[i].err = p.probe(ctx, cred)
+		}()
+	}
+	wg.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i, cred := range due {
+		state, found := p.states[cred.registry]
+		if !found {
+			// Forgotten by a concurrent call while probing
+			continue
+		}
+		if err := outcomes[i].err; err != nil {
+			state.failures++
+			retry := probeRetryInterval << (state.failures - 1)
+			if retry > interval || retry <= 0 {
+				retry = interval
+			}
+			state.nextProbe = now.Add(retry)
+			log.Info("Failed to probe registry credential", "registry", cred.registry, "failures", state.failures,
+				"retryIn", retry, "error", err.Error())
+			continue
+		}
+		state.failures = 0
+		state.nextProbe = now.Add(interval)
+		state.result = &ProbeResult{Registry: cred.registry, OK: outcomes[i].ok, LastProbe: now}
+		if !outcomes[i].ok {
+			log.Info("Registry rejected the credential of the pull secret", "registry", cred.registry)
+		}
+	}
+
+	results := make([]ProbeResult, 0, len(credentials))
+	for _, state := range p.states {
+		if state.result != nil {
+			results = append(results, *state.result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Registry < results[j].Registry })
+	return results
+}
+
+// probeOutcome is the outcome of a single probe
+type probeOutcome struct {
+	ok  bool
+	err error
+}
+
+// dueCredentials forgets registries which are no longer part of credentials and returns the credentials
+// whose probe is due. Their next probe is postponed by interval, so concurrent calls do not probe them again.
+func (p *Prober) dueCredentials(credentials []credential, now time.Time, interval time.Duration) []credential {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.states == nil {
+		p.states = map[string]*probeState{}
+	}
+	current := map[string]bool{}
+	var due []credential
+	for _, cred := range credentials {
+		current[cred.registry] = true
+		state, found := p.states[cred.registry]
+		if !found {
+			state = &probeState{}
+			p.states[cred.registry] = state
+		}
+		if now.Before(state.nextProbe) {
+			continue
+		}
+		state.nextProbe = now.Add(interval)
+		due = append(due, cred)
+	}
+	for registry := range p.states {
+		if !current[registry] {
+			delete(p.states, registry)
+		}
+	}
+	return due
+}
+
+// probe returns whether the registry accepts the credential, or an error if the registry could not
+// tell, e.g. as it is unreachable
+func (p *Prober) probe(ctx context.Context, cred credential) (bool, error) {
+	base := p.endpoint(cred.registry)
+	resp, err := p.get(ctx, base+"/v2/", nil)
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The registry does not require any credential
+		return true, nil
+	case http.StatusUnauthorized:
+	default:
+		return false, fmt.Errorf("unexpected status %d of %s/v2/", resp.StatusCode, base)
+	}
+
+	// Registries either take the credential right away, or exchange it for a token at their token endpoint
+	target := base + "/v2/"
+	scheme, params := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	switch scheme {
+	case "basic":
+	case "bearer":
+		target, err = tokenURL(base, params, cred.username)
+		if err != nil {
+			return false, fmt.Errorf("invalid token endpoint of %s: %w", cred.registry, err)
+		}
+	default:
+		return false, fmt.Errorf("unsupported authentication scheme %q of %s", scheme, cred.registry)
+	}
+	resp, err = p.get(ctx, target, &cred)
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected status %d authenticating to %s", resp.StatusCode, cred.registry)
+}
+
+// tokenURL returns the URL of the token request for the account username, as docker login does. The
+// credential is sent to the token endpoint, which therefore has to be served over https by the registry
+// host itself, or by the token service of Docker Hub.
+func tokenURL(base string, params map[string]string, username string) (string, error) {
+	if params["realm"] == "" {
+		return "", fmt.Errorf("missing realm")
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	if realm.Scheme != "https" {
+		return "", fmt.Errorf("refusing realm %s not using https", realm.Redacted())
+	}
+	registry, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	if realm.Hostname() != registry.Hostname() && !(base == dockerHubEndpoint && realm.Hostname() == dockerHubAuthHost) {
+		return "", fmt.Errorf("refusing realm %s not served by %s", realm.Redacted(), registry.Hostname())
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("account", username)
+	realm.RawQuery = query.Encode()
+	return realm.String(), nil
+}
+
+// get sends a GET request to target, authenticated with cred if not nil, and closes the response body
+func (p *Prober) get(ctx context.Context, target string, cred *credential) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cred != nil {
+		req.SetBasicAuth(cred.username, cred.password)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, resp.Body.Close()
+}
+
+// endpoint returns the base URL of the v2 API of registry
+func (p *Prober) endpoint(registry string) string {
+	if endpoint, ok := p.Endpoints[registry]; ok {
+		return strings.TrimSuffix(endpoint, "/")
+	}
+	// Entries may be URLs or hold a repository path, e.g. "https://quay.io/org"
+	host, _, _ := strings.Cut(normalizeRegistry(registry), "/")
+	if dockerHubRegistries[host] {
+		return dockerHubEndpoint
+	}
+	return "https://" + host
+}
+
+// parseChallenge returns the lower-cased scheme and the parameters of a WWW-Authenticate challenge,
+// e.g. `Bearer realm="https://auth.example.com/token",service="registry.example.com"`
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			// Quoted values may hold commas, e.g. scopes
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+		value, rest, _ = strings.Cut(value, ",")
+		params[key] = strings.TrimSpace(value)
+	}
+	return strings.ToLower(scheme), params
+}
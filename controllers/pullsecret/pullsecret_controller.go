@@ -17,12 +17,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
 	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -46,7 +52,8 @@ const (
 	ReasonEmptyCredential = "EmptyCredential" // #nosec G101 -- this is a metric label, not a credential
 )
 
-var expectedRegistries = []string{
+// defaultExpectedRegistries are the registries expected in the pull secret unless configured otherwise
+var defaultExpectedRegistries = []string{
 	"cloud.openshift.com",
 	"quay.io",
 	"registry.redhat.io",
@@ -61,15 +68,49 @@ type dockerConfigJSON struct {
 
 // dockerConfigAuth represents a single registry auth entry
 type dockerConfigAuth struct {
-	Auth string `json:"auth"`
+	Auth     string `json:"auth"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
 }
 
 // PullSecretReconciler reconciles the cluster pull secret
 type PullSecretReconciler struct {
 	client.Client
-	Scheme            *runtime.Scheme
-	MetricsAggregator *metrics.AdoptionMetricsAggregator
-	ClusterId         string
+	Scheme    *runtime.Scheme
+	Metrics   *Collector
+	ClusterId string
+
+	// expectedRegistriesOverride overrides the registries the pull secret must hold credentials for
+	expectedRegistriesOverride []string
+	// Prober probes the credentials of the pull secret once enabled by the configuration, nil disables probing
+	Prober *Prober
+	// Recorder records an event on the pull secret on every change of its entries, nil disables the events
+	Recorder record.EventRecorder
+
+	changes changeTracker
+
+	// optionalRegistries are the registries whose presence is reported without being required
+	optionalRegistries []string
+	probingEnabled     bool
+	probeInterval      time.Duration
+	configMutex        sync.Mutex
+}
+
+// validation is the outcome of the validation of the pull secret
+type validation struct {
+	valid bool
+	// reason is a structured label value, empty if the pull secret is valid
+	reason string
+	// missingRegistries are the required registries without any entry
+	missingRegistries []string
+	// emptyCredentials are the required registries whose entries all lack credentials
+	emptyCredentials []string
+	// invalidEntries holds the reason of every registry whose credential is invalid
+	invalidEntries map[string]string
+	// entries is what is reported on the entries, nil if they could not be read
+	entries *Entries
+	// credentials are the valid credentials of the entries, they must never be logged
+	credentials []credential
 }
 
 // Reconcile reads the pull secret and validates its structure and registry entries
@@ -83,53 +124,193 @@ func (r *PullSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Pull secret not found, marking as invalid")
-			r.MetricsAggregator.SetPullSecretValid(r.ClusterId, false, ReasonNotFound)
+			r.Metrics.SetPullSecretValid(r.ClusterId, false, ReasonNotFound)
+			r.Metrics.SetEntries(r.ClusterId, nil)
+			r.Metrics.SetChanges(r.ClusterId, nil)
+			r.probeCredentials(ctx, nil)
 			return reconcile.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
-	valid, reason := validatePullSecret(secret)
-	if !valid {
-		reqLogger.Info(fmt.Sprintf("Pull secret is invalid: %s", reason))
-		r.MetricsAggregator.SetPullSecretValid(r.ClusterId, false, reason)
+	r.trackChanges(secret)
+	result := validatePullSecret(secret, r.registryPolicy())
+	r.Metrics.SetEntries(r.ClusterId, result.entries)
+	r.probeCredentials(ctx, result.credentials)
+	if result.entries != nil && len(result.entries.UnknownFields) > 0 {
+		reqLogger.Info("Pull secret holds unknown fields", "fields", result.entries.UnknownFields)
+	}
+	if !result.valid {
+		reqLogger.Info(fmt.Sprintf("Pull secret is invalid: %s", result.reason),
+			"missingRegistries", result.missingRegistries, "emptyCredentials", result.emptyCredentials,
+			"invalidEntries", result.invalidEntries)
+		r.Metrics.SetPullSecretValid(r.ClusterId, false, result.reason)
 		return ctrl.Result{}, nil
 	}
 
 	reqLogger.Info("Pull secret is valid")
-	r.MetricsAggregator.SetPullSecretValid(r.ClusterId, true, ReasonValid)
+	r.Metrics.SetPullSecretValid(r.ClusterId, true, ReasonValid)
 	return ctrl.Result{}, nil
 }
 
-// validatePullSecret checks integrity and presence of expected registries.
-// Returns (valid bool, reason string) where reason is a structured label value.
-func validatePullSecret(secret *corev1.Secret) (bool, string) {
+// ApplyConfig applies the pull secret settings of the exporter configuration to the following reconciles
+func (r *PullSecretReconciler) ApplyConfig(spec *v1alpha1.MetricsExporterConfigSpec) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+	r.expectedRegistriesOverride = nil
+	r.optionalRegistries = nil
+	r.probingEnabled = false
+	r.probeInterval = DefaultProbeInterval
+	if spec.PullSecret != nil {
+		r.expectedRegistriesOverride = spec.PullSecret.ExpectedRegistries
+		r.optionalRegistries = spec.PullSecret.OptionalRegistries
+		r.probingEnabled = spec.PullSecret.ProbeCredentials
+		if spec.PullSecret.ProbeInterval != nil && spec.PullSecret.ProbeInterval.Duration > 0 {
+			r.probeInterval = spec.PullSecret.ProbeInterval.Duration
+		}
+	}
+	if invalid := r.registryPolicyLocked().invalidPatterns(); len(invalid) > 0 {
+		log.Info("Invalid registry patterns only match registries of the same name", "patterns", invalid)
+	}
+}
+
+func (r *PullSecretReconciler) registryPolicy() registryPolicy {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+	return r.registryPolicyLocked()
+}
+
+// registryPolicyLocked returns the configured registry policy, configMutex has to be held
+func (r *PullSecretReconciler) registryPolicyLocked() registryPolicy {
+	policy := registryPolicy{required: defaultExpectedRegistries, optional: r.optionalRegistries}
+	if len(r.expectedRegistriesOverride) > 0 {
+		policy.required = r.expectedRegistriesOverride
+	}
+	return policy
+}
+
+// probeCredentials probes credentials if probing is enabled, and reports the results
+func (r *PullSecretReconciler) probeCredentials(ctx context.Context, credentials []credential) {
+	r.configMutex.Lock()
+	enabled, interval := r.probingEnabled, r.probeInterval
+	r.configMutex.Unlock()
+	if r.Prober == nil || !enabled {
+		r.Metrics.SetProbeResults(r.ClusterId, nil)
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultProbeInterval
+	}
+	r.Metrics.SetProbeResults(r.ClusterId, r.Prober.Probe(ctx, credentials, interval))
+}
+
+// trackChanges fingerprints the entries of secret and reports their changes. Every change is logged and
+// recorded as an event on secret, with the registries whose entry changed but never their credentials.
+func (r *PullSecretReconciler) trackChanges(secret *corev1.Secret) {
+	fingerprints := r.changes.fingerprintEntries(secret.Data[dockerConfigJSONKey])
+	if fingerprints == nil {
+		r.Metrics.SetChanges(r.ClusterId, nil)
+		return
+	}
+	changes, change := r.changes.observe(secret, fingerprints, time.Now())
+	r.Metrics.SetChanges(r.ClusterId, changes)
+	if change.empty() {
+		return
+	}
+	log.Info("Pull secret entries changed", "added", change.added, "removed", change.removed,
+		"rotated", change.rotated, "registries", changes.Registries, "rotations", changes.Rotations)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(secret, corev1.EventTypeNormal, pullSecretChangedEventReason,
+			"Pull secret entries changed (rotation %d): %d registries, added %v, removed %v, rotated %v",
+			changes.Rotations, changes.Registries, change.added, change.removed, change.rotated)
+	}
+}
+
+// validatePullSecret checks integrity and presence of the registries of policy, and the credential of
+// every entry. A required registry is satisfied by any matching entry holding a credential. Invalid
+// credentials only invalidate the pull secret for entries of required registries, the others are only
+// reported as invalid entries.
+func validatePullSecret(secret *corev1.Secret, policy registryPolicy) validation {
 	data, ok := secret.Data[dockerConfigJSONKey]
 	if !ok {
-		return false, ReasonMissingKey
+		return validation{reason: ReasonMissingKey}
 	}
 
 	var config dockerConfigJSON
 	if err := json.Unmarshal(data, &config); err != nil {
-		return false, ReasonMalformedJSON
+		return validation{reason: ReasonMalformedJSON}
 	}
 
+	result := validation{
+		invalidEntries: map[string]string{},
+		entries: &Entries{
+			Registries:     policy.presence(config.Auths),
+			InvalidEntries: map[string]int{},
+			UnknownFields:  unknownFields(data),
+		},
+	}
 	if len(config.Auths) == 0 {
-		return false, ReasonEmptyAuths
+		result.reason = ReasonEmptyAuths
+		return result
 	}
 
-	// Check that each expected registry is present with a non-empty auth token
-	for _, registry := range expectedRegistries {
-		auth, ok := config.Auths[registry]
-		if !ok {
-			return false, ReasonMissingRegistry
+	// Check that each required registry is present with a non-empty auth token, the first
+	// failing registry determines the reason
+	required := map[string]bool{}
+	for _, pattern := range policy.required {
+		registries := matchingRegistries(pattern, config.Auths)
+		for _, registry := range registries {
+			required[registry] = true
+		}
+		if len(registries) == 0 {
+			result.missingRegistries = append(result.missingRegistries, pattern)
+			if result.reason == "" {
+				result.reason = ReasonMissingRegistry
+			}
+			continue
 		}
+		if !hasCredential(registries, config.Auths) {
+			result.emptyCredentials = append(result.emptyCredentials, pattern)
+			if result.reason == "" {
+				result.reason = ReasonEmptyCredential
+			}
+		}
+	}
+
+	// Entries without auth token are only rejected if required, as above
+	registries := make([]string, 0, len(config.Auths))
+	for registry := range config.Auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	for _, registry := range registries {
+		auth := config.Auths[registry]
 		if auth.Auth == "" {
-			return false, ReasonEmptyCredential
+			continue
 		}
+		username, password, reason := decodeCredential(auth)
+		if reason != "" {
+			result.invalidEntries[registry] = reason
+			result.entries.InvalidEntries[reason]++
+			if required[registry] && result.reason == "" {
+				result.reason = reason
+			}
+			continue
+		}
+		result.credentials = append(result.credentials, credential{registry: registry, username: username, password: password})
 	}
+	result.valid = result.reason == ""
+	return result
+}
 
-	return true, ""
+// hasCredential returns whether any of registries holds a non-empty auth token
+func hasCredential(registries []string, auths map[string]dockerConfigAuth) bool {
+	for _, registry := range registries {
+		if auths[registry].Auth != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -150,5 +331,8 @@ func (r *PullSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return evt.Object.GetName() == pullSecretName && evt.Object.GetNamespace() == pullSecretNamespace
 			},
 		}).
-		Complete(r)
+		WatchesRawSource(r.Metrics.ResyncSource(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pullSecretNamespace, Name: pullSecretName},
+		})).
+		Complete(metrics.NewReconciler(r.Metrics, r))
 }
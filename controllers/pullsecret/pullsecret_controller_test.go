@@ -15,11 +15,16 @@ package pullsecret
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -27,6 +32,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -159,17 +165,15 @@ pull_secret_valid{_id="test-cluster-id",name="osd_exporter",reason="MissingKey"}
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			metricsAggregator := metrics.NewMetricsAggregator(time.Second, testClusterId)
-			done := metricsAggregator.Run()
-			defer close(done)
+			collector := NewCollector()
 			err := corev1.AddToScheme(scheme.Scheme)
 			require.NoError(t, err)
 
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.secret).Build()
 			reconciler := PullSecretReconciler{
-				Client:            fakeClient,
-				MetricsAggregator: metricsAggregator,
-				ClusterId:         testClusterId,
+				Client:    fakeClient,
+				Metrics:   collector,
+				ClusterId: testClusterId,
 			}
 			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{
@@ -178,34 +182,61 @@ pull_secret_valid{_id="test-cluster-id",name="osd_exporter",reason="MissingKey"}
 				},
 			})
 
-			// sleep to allow the aggregator to aggregate metrics in the background
-			time.Sleep(time.Second * 3)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			var testSecret corev1.Secret
 			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: pullSecretName, Namespace: pullSecretNamespace}, &testSecret)
 			require.NoError(t, err)
 
-			metric := metricsAggregator.GetPullSecretValidMetric()
-			err = testutil.CollectAndCompare(metric, strings.NewReader(tc.expectedResults))
+			err = testutil.CollectAndCompare(collector, strings.NewReader(tc.expectedResults), "pull_secret_valid")
 			require.NoError(t, err)
 		})
 	}
 }
 
+func TestReconcilePullSecretExpectedRegistries_Reconcile(t *testing.T) {
+	collector := NewCollector()
+	err := corev1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	secret := makeTestPullSecret([]byte(`{"auths": {"mirror.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+	reconciler := PullSecretReconciler{
+		Client:    fakeClient,
+		Metrics:   collector,
+		ClusterId: testClusterId,
+	}
+	reconciler.ApplyConfig(&v1alpha1.MetricsExporterConfigSpec{
+		PullSecret: &v1alpha1.PullSecretConfig{ExpectedRegistries: []string{"mirror.example.com"}},
+	})
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: pullSecretNamespace,
+			Name:      pullSecretName,
+		},
+	})
+	require.NoError(t, err)
+
+	expectedResults := `
+# HELP pull_secret_valid Indicates if the cluster pull secret is valid (1=valid, 0=invalid)
+# TYPE pull_secret_valid gauge
+pull_secret_valid{_id="test-cluster-id",name="osd_exporter",reason="Valid"} 1
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expectedResults), "pull_secret_valid")
+	require.NoError(t, err)
+}
+
 func TestReconcilePullSecretNotFound_Reconcile(t *testing.T) {
-	metricsAggregator := metrics.NewMetricsAggregator(time.Second, testClusterId)
-	done := metricsAggregator.Run()
-	defer close(done)
+	collector := NewCollector()
 	err := corev1.AddToScheme(scheme.Scheme)
 	require.NoError(t, err)
 
 	// No pull secret object created - simulate missing secret
 	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
 	reconciler := PullSecretReconciler{
-		Client:            fakeClient,
-		MetricsAggregator: metricsAggregator,
-		ClusterId:         testClusterId,
+		Client:    fakeClient,
+		Metrics:   collector,
+		ClusterId: testClusterId,
 	}
 	result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 		NamespacedName: types.NamespacedName{
@@ -214,8 +245,6 @@ func TestReconcilePullSecretNotFound_Reconcile(t *testing.T) {
 		},
 	})
 
-	// sleep to allow the aggregator to aggregate metrics in the background
-	time.Sleep(time.Second * 3)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 	var testSecret corev1.Secret
@@ -227,8 +256,7 @@ func TestReconcilePullSecretNotFound_Reconcile(t *testing.T) {
 # TYPE pull_secret_valid gauge
 pull_secret_valid{_id="test-cluster-id",name="osd_exporter",reason="SecretNotFound"} 0
 `
-	metric := metricsAggregator.GetPullSecretValidMetric()
-	err = testutil.CollectAndCompare(metric, strings.NewReader(expectedResults))
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expectedResults), "pull_secret_valid")
 	require.NoError(t, err)
 }
 
@@ -293,14 +321,731 @@ func TestValidatePullSecret(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			valid, reason := validatePullSecret(tc.secret)
+			result := validatePullSecret(tc.secret, registryPolicy{required: defaultExpectedRegistries})
 			if tc.expectValid {
-				require.True(t, valid, "expected valid but got invalid: %s", reason)
-				require.Empty(t, reason)
+				require.True(t, result.valid, "expected valid but got invalid: %s", result.reason)
+				require.Empty(t, result.reason)
 			} else {
-				require.False(t, valid, "expected invalid but got valid")
-				require.Equal(t, tc.expectedReason, reason)
+				require.False(t, result.valid, "expected invalid but got valid")
+				require.Equal(t, tc.expectedReason, result.reason)
 			}
 		})
 	}
 }
+
+func TestValidatePullSecret_RegistryPolicy(t *testing.T) {
+	secret := makeTestPullSecret([]byte(`{
+		"auths": {
+			"mirror-1.example.com": {"auth": ""},
+			"mirror-2.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"quay.io": {"auth": ""},
+			"registry.example.com:5000": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}
+		}
+	}`))
+	for _, tc := range []struct {
+		name                      string
+		policy                    registryPolicy
+		expectedReason            string
+		expectedMissingRegistries []string
+		expectedEmptyCredentials  []string
+		expectedRegistries        []RegistryPresence
+	}{
+		{
+			name:   "glob satisfied by any entry holding a credential",
+			policy: registryPolicy{required: []string{"mirror-*.example.com", "registry.example.com:*"}},
+			expectedRegistries: []RegistryPresence{
+				{Registry: "mirror-*.example.com", Required: true, Present: true},
+				{Registry: "registry.example.com:*", Required: true, Present: true},
+			},
+		},
+		{
+			name:                      "every missing registry is reported",
+			policy:                    registryPolicy{required: []string{"cloud.openshift.com", "quay.io", "*.redhat.io"}},
+			expectedReason:            ReasonMissingRegistry,
+			expectedMissingRegistries: []string{"cloud.openshift.com", "*.redhat.io"},
+			expectedEmptyCredentials:  []string{"quay.io"},
+			expectedRegistries: []RegistryPresence{
+				{Registry: "cloud.openshift.com", Required: true, Present: false},
+				{Registry: "quay.io", Required: true, Present: true},
+				{Registry: "*.redhat.io", Required: true, Present: false},
+			},
+		},
+		{
+			name:                      "first failing registry determines the reason",
+			policy:                    registryPolicy{required: []string{"quay.io", "cloud.openshift.com"}},
+			expectedReason:            ReasonEmptyCredential,
+			expectedEmptyCredentials:  []string{"quay.io"},
+			expectedMissingRegistries: []string{"cloud.openshift.com"},
+			expectedRegistries: []RegistryPresence{
+				{Registry: "quay.io", Required: true, Present: true},
+				{Registry: "cloud.openshift.com", Required: true, Present: false},
+			},
+		},
+		{
+			name:   "optional registries are reported without being required",
+			policy: registryPolicy{required: []string{"mirror-*.example.com"}, optional: []string{"*.redhat.io", "quay.io"}},
+			expectedRegistries: []RegistryPresence{
+				{Registry: "mirror-*.example.com", Required: true, Present: true},
+				{Registry: "*.redhat.io", Required: false, Present: false},
+				{Registry: "quay.io", Required: false, Present: true},
+			},
+		},
+		{
+			name:                      "invalid pattern only matches itself",
+			policy:                    registryPolicy{required: []string{"[quay.io"}},
+			expectedReason:            ReasonMissingRegistry,
+			expectedMissingRegistries: []string{"[quay.io"},
+			expectedRegistries: []RegistryPresence{
+				{Registry: "[quay.io", Required: true, Present: false},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := validatePullSecret(secret, tc.policy)
+			require.Equal(t, tc.expectedReason == "", result.valid)
+			require.Equal(t, tc.expectedReason, result.reason)
+			require.Equal(t, tc.expectedMissingRegistries, result.missingRegistries)
+			require.Equal(t, tc.expectedEmptyCredentials, result.emptyCredentials)
+			require.Equal(t, tc.expectedRegistries, result.entries.Registries)
+		})
+	}
+}
+
+func TestReconcilePullSecretRegistryPresent_Reconcile(t *testing.T) {
+	collector := NewCollector()
+	err := corev1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	secret := makeTestPullSecret([]byte(`{"auths": {"mirror.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`))
+	reconciler := PullSecretReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+		Metrics:   collector,
+		ClusterId: testClusterId,
+	}
+	reconciler.ApplyConfig(&v1alpha1.MetricsExporterConfigSpec{
+		PullSecret: &v1alpha1.PullSecretConfig{
+			ExpectedRegistries: []string{"*.example.com", "quay.io"},
+			OptionalRegistries: []string{"registry.redhat.io"},
+		},
+	})
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName},
+	})
+	require.NoError(t, err)
+
+	expectedResults := `
+# HELP pull_secret_registry_present Indicates if the cluster pull secret holds an entry for a registry pattern of the registry policy
+# TYPE pull_secret_registry_present gauge
+pull_secret_registry_present{_id="test-cluster-id",name="osd_exporter",registry="*.example.com",required="true"} 1
+pull_secret_registry_present{_id="test-cluster-id",name="osd_exporter",registry="quay.io",required="true"} 0
+pull_secret_registry_present{_id="test-cluster-id",name="osd_exporter",registry="registry.redhat.io",required="false"} 0
+# HELP pull_secret_valid Indicates if the cluster pull secret is valid (1=valid, 0=invalid)
+# TYPE pull_secret_valid gauge
+pull_secret_valid{_id="test-cluster-id",name="osd_exporter",reason="MissingRegistry"} 0
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expectedResults), "pull_secret_registry_present", "pull_secret_valid")
+	require.NoError(t, err)
+
+	// The registries are no longer reported once the entries cannot be read
+	require.NoError(t, reconciler.Delete(context.TODO(), secret))
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, testutil.CollectAndCount(collector, "pull_secret_registry_present"))
+}
+
+func TestDecodeCredential(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	for _, tc := range []struct {
+		name           string
+		auth           dockerConfigAuth
+		expectedReason string
+	}{
+		{
+			name: "valid credential",
+			auth: dockerConfigAuth{Auth: encode("user:pass")},
+		},
+		{
+			name: "password holding colons",
+			auth: dockerConfigAuth{Auth: encode("user:pa:ss")},
+		},
+		{
+			name: "matching username and password fields",
+			auth: dockerConfigAuth{Auth: encode("user:pass"), Username: "user", Password: "pass"},
+		},
+		{
+			name:           "invalid base64",
+			auth:           dockerConfigAuth{Auth: "not base64!"},
+			expectedReason: ReasonInvalidBase64,
+		},
+		{
+			name:           "token without colon",
+			auth:           dockerConfigAuth{Auth: encode("token")},
+			expectedReason: ReasonMalformedCredential,
+		},
+		{
+			name:           "empty username",
+			auth:           dockerConfigAuth{Auth: encode(":pass")},
+			expectedReason: ReasonEmptyUsername,
+		},
+		{
+			name:           "empty password",
+			auth:           dockerConfigAuth{Auth: encode("user:")},
+			expectedReason: ReasonEmptyPassword,
+		},
+		{
+			name:           "username field disagrees",
+			auth:           dockerConfigAuth{Auth: encode("user:pass"), Username: "other"},
+			expectedReason: ReasonCredentialMismatch,
+		},
+		{
+			name:           "password field disagrees",
+			auth:           dockerConfigAuth{Auth: encode("user:pass"), Password: "other"},
+			expectedReason: ReasonCredentialMismatch,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, reason := decodeCredential(tc.auth)
+			require.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	data := []byte(`{
+		"auths": {
+			"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M=", "email": "user@example.com", "token": "x"},
+			"registry.redhat.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M=", "identitytoken": "x"}
+		},
+		"credsStore": "desktop"
+	}`)
+	require.Equal(t, []string{"auths.quay.io.token", "credsStore"}, unknownFields(data))
+	require.Empty(t, unknownFields(makeValidDockerConfigJSON()))
+}
+
+func TestReconcilePullSecretInvalidEntries_Reconcile(t *testing.T) {
+	collector := NewCollector()
+	err := corev1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	// Every required registry holds a credential, while two entries are broken
+	secret := makeTestPullSecret([]byte(`{
+		"auths": {
+			"cloud.openshift.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M=", "username": "someone-else"},
+			"registry.redhat.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"registry.connect.redhat.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"mirror.example.com": {"auth": "%%%", "token": "x"}
+		}
+	}`))
+	reconciler := PullSecretReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+		Metrics:   collector,
+		ClusterId: testClusterId,
+	}
+	_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName},
+	})
+	require.NoError(t, err)
+
+	// Only the broken entry of a required registry invalidates the pull secret
+	expectedResults := `
+# HELP pull_secret_invalid_entries Number of entries of the cluster pull secret holding an invalid credential, by reason
+# TYPE pull_secret_invalid_entries gauge
+pull_secret_invalid_entries{_id="test-cluster-id",name="osd_exporter",reason="CredentialMismatch"} 1
+pull_secret_invalid_entries{_id="test-cluster-id",name="osd_exporter",reason="EmptyPassword"} 0
+pull_secret_invalid_entries{_id="test-cluster-id",name="osd_exporter",reason="EmptyUsername"} 0
+pull_secret_invalid_entries{_id="test-cluster-id",name="osd_exporter",reason="InvalidBase64"} 1
+pull_secret_invalid_entries{_id="test-cluster-id",name="osd_exporter",reason="MalformedCredential"} 0
+# HELP pull_secret_unknown_fields Number of fields of the cluster pull secret and its entries not understood by the container runtimes
+# TYPE pull_secret_unknown_fields gauge
+pull_secret_unknown_fields{_id="test-cluster-id",name="osd_exporter"} 1
+# HELP pull_secret_valid Indicates if the cluster pull secret is valid (1=valid, 0=invalid)
+# TYPE pull_secret_valid gauge
+pull_secret_valid{_id="test-cluster-id",name="osd_exporter",reason="CredentialMismatch"} 0
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expectedResults),
+		"pull_secret_invalid_entries", "pull_secret_unknown_fields", "pull_secret_valid")
+	require.NoError(t, err)
+}
+
+func TestValidatePullSecret_InvalidEntries(t *testing.T) {
+	secret := makeTestPullSecret([]byte(`{
+		"auths": {
+			"mirror.example.com": {"auth": "%%%"},
+			"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"registry.example.com": {"auth": "dGVzdHVzZXI="}
+		}
+	}`))
+	for _, tc := range []struct {
+		name           string
+		policy         registryPolicy
+		expectedReason string
+	}{
+		{
+			name:   "invalid unlisted entries are only reported",
+			policy: registryPolicy{required: []string{"quay.io"}},
+		},
+		{
+			name:   "invalid optional entries are only reported",
+			policy: registryPolicy{required: []string{"quay.io"}, optional: []string{"*.example.com"}},
+		},
+		{
+			name:           "first invalid required entry determines the reason",
+			policy:         registryPolicy{required: []string{"quay.io", "*.example.com"}},
+			expectedReason: ReasonInvalidBase64,
+		},
+		{
+			name:           "invalid entry matching a required pattern",
+			policy:         registryPolicy{required: []string{"quay.io", "registry.*"}},
+			expectedReason: ReasonMalformedCredential,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := validatePullSecret(secret, tc.policy)
+			require.Equal(t, tc.expectedReason == "", result.valid)
+			require.Equal(t, tc.expectedReason, result.reason)
+			require.Equal(t, map[string]string{
+				"mirror.example.com":   ReasonInvalidBase64,
+				"registry.example.com": ReasonMalformedCredential,
+			}, result.invalidEntries)
+			require.Equal(t, 1, result.entries.InvalidEntries[ReasonInvalidBase64])
+			require.Equal(t, 1, result.entries.InvalidEntries[ReasonMalformedCredential])
+		})
+	}
+}
+
+// newTestRegistry starts a stand-in of a registry accepting testuser:testpass. Registries named "basic"
+// take the credential right away, "bearer" registries exchange it at their token endpoint, "anonymous"
+// registries require none, "broken" registries always fail and "slow" registries never answer in time.
+// hits counts the requests.
+func newTestRegistry(t *testing.T, kind string, hits *atomic.Int32) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		username, password, ok := r.BasicAuth()
+		authorized := ok && username == "testuser" && password == "testpass"
+		switch {
+		case kind == "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case kind == "slow":
+			<-r.Context().Done()
+		case kind == "anonymous":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/token" && kind == "bearer":
+			if !authorized || r.URL.Query().Get("service") != "registry.example.com" || r.URL.Query().Get("account") != username {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token": "token"}`))
+		case kind == "basic" && authorized:
+			w.WriteHeader(http.StatusOK)
+		case kind == "basic":
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.example.com"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProber_Probe(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		kind           string
+		password       string
+		expectedResult bool
+		expectedProbed bool
+	}{
+		{name: "basic auth accepts credential", kind: "basic", password: "testpass", expectedResult: true, expectedProbed: true},
+		{name: "basic auth rejects credential", kind: "basic", password: "revoked", expectedResult: false, expectedProbed: true},
+		{name: "token endpoint accepts credential", kind: "bearer", password: "testpass", expectedResult: true, expectedProbed: true},
+		{name: "token endpoint rejects credential", kind: "bearer", password: "revoked", expectedResult: false, expectedProbed: true},
+		{name: "registry without authentication", kind: "anonymous", password: "revoked", expectedResult: true, expectedProbed: true},
+		{name: "failing registry is inconclusive", kind: "broken", password: "testpass"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var hits atomic.Int32
+			server := newTestRegistry(t, tc.kind, &hits)
+			prober := &Prober{Client: server.Client(), Endpoints: map[string]string{"registry.example.com": server.URL}}
+			results := prober.Probe(context.TODO(), []credential{
+				{registry: "registry.example.com", username: "testuser", password: tc.password},
+			}, time.Hour)
+			if !tc.expectedProbed {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, "registry.example.com", results[0].Registry)
+			require.Equal(t, tc.expectedResult, results[0].OK)
+			require.WithinDuration(t, time.Now(), results[0].LastProbe, time.Minute)
+		})
+	}
+}
+
+func TestProber_Schedule(t *testing.T) {
+	var hits atomic.Int32
+	server := newTestRegistry(t, "basic", &hits)
+	prober := &Prober{Client: server.Client(), Endpoints: map[string]string{"registry.example.com": server.URL}}
+	credentials := []credential{{registry: "registry.example.com", username: "testuser", password: "testpass"}}
+
+	// A conclusive probe is not repeated within the probe interval
+	first := prober.Probe(context.TODO(), credentials, time.Hour)
+	require.Equal(t, int32(2), hits.Load())
+	require.Equal(t, first, prober.Probe(context.TODO(), credentials, time.Hour))
+	require.Equal(t, int32(2), hits.Load())
+
+	// Registries which are no longer part of the pull secret are forgotten
+	require.Empty(t, prober.Probe(context.TODO(), nil, time.Hour))
+	require.Empty(t, prober.states)
+}
+
+func TestProber_Backoff(t *testing.T) {
+	var hits atomic.Int32
+	server := newTestRegistry(t, "broken", &hits)
+	prober := &Prober{Client: server.Client(), Endpoints: map[string]string{"registry.example.com": server.URL}}
+	credentials := []credential{{registry: "registry.example.com", username: "testuser", password: "testpass"}}
+
+	for i, expectedRetry := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute} {
+		prober.Probe(context.TODO(), credentials, 5*time.Minute)
+		require.Equal(t, int32(i+1), hits.Load())
+		state := prober.states["registry.example.com"]
+		require.Equal(t, i+1, state.failures)
+		require.WithinDuration(t, time.Now().Add(expectedRetry), state.nextProbe, time.Second)
+
+		// The failing probe is not retried before its backoff expired
+		prober.Probe(context.TODO(), credentials, 5*time.Minute)
+		require.Equal(t, int32(i+1), hits.Load())
+		state.nextProbe = time.Now()
+	}
+}
+
+func TestProber_Timeout(t *testing.T) {
+	var slowHits, fastHits atomic.Int32
+	slow := newTestRegistry(t, "slow", &slowHits)
+	fast := newTestRegistry(t, "basic", &fastHits)
+	prober := &Prober{Client: fast.Client(), Timeout: 100 * time.Millisecond, Endpoints: map[string]string{
+		"slow.example.com": slow.URL,
+		"fast.example.com": fast.URL,
+	}}
+
+	// A registry which never answers does not hold up the probes of other registries past the timeout
+	start := time.Now()
+	results := prober.Probe(context.TODO(), []credential{
+		{registry: "slow.example.com", username: "testuser", password: "testpass"},
+		{registry: "fast.example.com", username: "testuser", password: "testpass"},
+	}, time.Hour)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Len(t, results, 1)
+	require.Equal(t, "fast.example.com", results[0].Registry)
+	require.True(t, results[0].OK)
+	require.Equal(t, 1, prober.states["slow.example.com"].failures)
+}
+
+func TestTokenURL(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		base     string
+		realm    string
+		expected string
+	}{
+		{name: "realm of the registry host", base: "https://quay.io", realm: "https://quay.io/v2/auth",
+			expected: "https://quay.io/v2/auth?account=testuser&service=quay.io"},
+		{name: "token service of Docker Hub", base: dockerHubEndpoint, realm: "https://auth.docker.io/token",
+			expected: "https://auth.docker.io/token?account=testuser&service=quay.io"},
+		{name: "realm not using https", base: "https://quay.io", realm: "http://quay.io/v2/auth"},
+		{name: "realm of another host", base: "https://quay.io", realm: "https://attacker.example.com/token"},
+		{name: "Docker Hub token service for another registry", base: "https://quay.io", realm: "https://auth.docker.io/token"},
+		{name: "missing realm", base: "https://quay.io"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			target, err := tokenURL(tc.base, map[string]string{"realm": tc.realm, "service": "quay.io"}, "testuser")
+			if tc.expected == "" {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, target)
+		})
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:org/image:pull,push"`)
+	require.Equal(t, "bearer", scheme)
+	require.Equal(t, map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:org/image:pull,push",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	require.Equal(t, "basic", scheme)
+	require.Equal(t, map[string]string{"realm": "registry"}, params)
+}
+
+func TestProber_Endpoint(t *testing.T) {
+	prober := NewProber()
+	require.Equal(t, "https://quay.io", prober.endpoint("quay.io"))
+	require.Equal(t, "https://quay.io", prober.endpoint("https://quay.io/org/"))
+	require.Equal(t, "https://registry.example.com:5000", prober.endpoint("registry.example.com:5000"))
+	require.Equal(t, dockerHubEndpoint, prober.endpoint("https://index.docker.io/v1/"))
+}
+
+func TestParseRegistries(t *testing.T) {
+	registries, err := ParseRegistries(makeTestPullSecret([]byte(`{
+		"auths": {
+			"registry.redhat.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"empty.example.com": {"auth": ""}
+		}
+	}`)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"quay.io", "registry.redhat.io"}, registries)
+
+	_, err = ParseRegistries(makeTestPullSecret([]byte(`{`)))
+	require.Error(t, err)
+	_, err = ParseRegistries(&corev1.Secret{})
+	require.Error(t, err)
+}
+
+func TestCredentialFor(t *testing.T) {
+	registries := []string{"quay.io", "quay.io/org", "https://index.docker.io/v1/", "registry.example.com:5000"}
+	for _, tc := range []struct {
+		repository string
+		expected   string
+	}{
+		{repository: "quay.io/other/image", expected: "quay.io"},
+		{repository: "quay.io/org/image", expected: "quay.io/org"},
+		{repository: "quay.io/organization/image", expected: "quay.io"},
+		{repository: "docker.io/library/busybox", expected: "https://index.docker.io/v1/"},
+		{repository: "registry.example.com:5000/image", expected: "registry.example.com:5000"},
+		{repository: "registry.example.com/image"},
+		{repository: "quay.io.example.com/image"},
+	} {
+		t.Run(tc.repository, func(t *testing.T) {
+			registry, ok := CredentialFor(registries, tc.repository)
+			require.Equal(t, tc.expected != "", ok)
+			require.Equal(t, tc.expected, registry)
+		})
+	}
+}
+
+func TestReconcilePullSecretProbe_Reconcile(t *testing.T) {
+	collector := NewCollector()
+	err := corev1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	var hits atomic.Int32
+	server := newTestRegistry(t, "bearer", &hits)
+	secret := makeTestPullSecret([]byte(`{
+		"auths": {
+			"mirror.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"revoked.example.com": {"auth": "dGVzdHVzZXI6cmV2b2tlZA=="}
+		}
+	}`))
+	reconciler := PullSecretReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+		Metrics:   collector,
+		ClusterId: testClusterId,
+		Prober: &Prober{Client: server.Client(), Endpoints: map[string]string{
+			"mirror.example.com":  server.URL,
+			"revoked.example.com": server.URL,
+		}},
+	}
+	reconcile := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName},
+		})
+		require.NoError(t, err)
+	}
+
+	// Probing is opt-in
+	reconcile()
+	require.Equal(t, int32(0), hits.Load())
+	require.Equal(t, 0, testutil.CollectAndCount(collector, "pull_secret_registry_auth_ok"))
+
+	reconciler.ApplyConfig(&v1alpha1.MetricsExporterConfigSpec{
+		PullSecret: &v1alpha1.PullSecretConfig{ExpectedRegistries: []string{"*.example.com"}, ProbeCredentials: true},
+	})
+	reconcile()
+	expectedResults := `
+# HELP pull_secret_registry_auth_ok Indicates if a registry accepted the credential of the cluster pull secret during the last conclusive probe
+# TYPE pull_secret_registry_auth_ok gauge
+pull_secret_registry_auth_ok{_id="test-cluster-id",name="osd_exporter",registry="mirror.example.com"} 1
+pull_secret_registry_auth_ok{_id="test-cluster-id",name="osd_exporter",registry="revoked.example.com"} 0
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expectedResults), "pull_secret_registry_auth_ok")
+	require.NoError(t, err)
+	require.Equal(t, 2, testutil.CollectAndCount(collector, "pull_secret_registry_last_probe_timestamp_seconds"))
+}
+
+func TestChangeTracker(t *testing.T) {
+	var tracker changeTracker
+	fingerprints := tracker.fingerprintEntries([]byte(`{"auths": {
+		"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M=", "email": "user@example.com"},
+		"registry.redhat.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}
+	}}`))
+	require.Len(t, fingerprints, 2)
+	// The same credential of another registry has another fingerprint
+	require.NotEqual(t, fingerprints["quay.io"], fingerprints["registry.redhat.io"])
+	for _, fingerprint := range fingerprints {
+		require.NotContains(t, fingerprint, "dGVzdHVzZXI6dGVzdHBhc3M=")
+	}
+	// Formatting is no change
+	require.Equal(t, fingerprints, tracker.fingerprintEntries([]byte(
+		`{"auths":{"registry.redhat.io":{"auth":"dGVzdHVzZXI6dGVzdHBhc3M="},`+
+			`"quay.io":{"email":"user@example.com","auth":"dGVzdHVzZXI6dGVzdHBhc3M="}}}`)))
+	require.Nil(t, tracker.fingerprintEntries([]byte(`{`)))
+	require.Nil(t, tracker.fingerprintEntries(nil))
+
+	// Fingerprints are keyed by tracker
+	var other changeTracker
+	require.NotEqual(t, fingerprints, other.fingerprintEntries([]byte(`{"auths": {
+		"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M=", "email": "user@example.com"},
+		"registry.redhat.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}
+	}}`)))
+
+	updated := metav1.NewTime(time.Unix(2000, 0))
+	secret := makeTestPullSecret(nil)
+	secret.CreationTimestamp = metav1.NewTime(time.Unix(1000, 0))
+	secret.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl", Time: &updated}}
+	now := time.Unix(3000, 0)
+
+	// The first observation is no change
+	changes, change := tracker.observe(secret, fingerprints, now)
+	require.True(t, change.empty())
+	require.Equal(t, &Changes{Registries: 2, LastChange: updated.Time}, changes)
+
+	changes, change = tracker.observe(secret, map[string]string{
+		"quay.io":             "rotated",
+		"cloud.openshift.com": fingerprints["registry.redhat.io"],
+	}, now)
+	require.Equal(t, entryChange{
+		added:   []string{"cloud.openshift.com"},
+		removed: []string{"registry.redhat.io"},
+		rotated: []string{"quay.io"},
+	}, change)
+	require.Equal(t, &Changes{Registries: 2, LastChange: now, Rotations: 1}, changes)
+
+	changes, change = tracker.observe(secret, map[string]string{
+		"quay.io":             "rotated",
+		"cloud.openshift.com": fingerprints["registry.redhat.io"],
+	}, now.Add(time.Hour))
+	require.True(t, change.empty())
+	require.Equal(t, &Changes{Registries: 2, LastChange: now, Rotations: 1}, changes)
+}
+
+func TestReconcilePullSecretChanges_Reconcile(t *testing.T) {
+	collector := NewCollector()
+	err := corev1.AddToScheme(scheme.Scheme)
+	require.NoError(t, err)
+
+	created := time.Unix(1000, 0)
+	secret := makeTestPullSecret([]byte(`{"auths": {"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`))
+	secret.CreationTimestamp = metav1.NewTime(created)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := PullSecretReconciler{
+		Client:    fakeClient,
+		Metrics:   collector,
+		ClusterId: testClusterId,
+		Recorder:  recorder,
+	}
+	reconcile := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName},
+		})
+		require.NoError(t, err)
+	}
+
+	reconcile()
+	expectedResults := `
+# HELP pull_secret_last_change_timestamp_seconds Unix timestamp of the last change of the entries of the cluster pull secret
+# TYPE pull_secret_last_change_timestamp_seconds gauge
+pull_secret_last_change_timestamp_seconds{_id="test-cluster-id",name="osd_exporter"} 1000
+# HELP pull_secret_registries Number of registry entries of the cluster pull secret
+# TYPE pull_secret_registries gauge
+pull_secret_registries{_id="test-cluster-id",name="osd_exporter"} 1
+# HELP pull_secret_rotations_total Number of changes of the entries of the cluster pull secret observed since the exporter started
+# TYPE pull_secret_rotations_total counter
+pull_secret_rotations_total{_id="test-cluster-id",name="osd_exporter"} 0
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expectedResults),
+		"pull_secret_last_change_timestamp_seconds", "pull_secret_registries", "pull_secret_rotations_total")
+	require.NoError(t, err)
+	require.Empty(t, recorder.Events)
+
+	// Rotating the credential and adding a registry is one change
+	secret.Data[dockerConfigJSONKey] = []byte(`{"auths": {
+		"quay.io": {"auth": "dGVzdHVzZXI6cm90YXRlZA=="},
+		"registry.redhat.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}
+	}}`)
+	require.NoError(t, fakeClient.Update(context.TODO(), secret))
+	reconcile()
+	expectedResults = `
+# HELP pull_secret_registries Number of registry entries of the cluster pull secret
+# TYPE pull_secret_registries gauge
+pull_secret_registries{_id="test-cluster-id",name="osd_exporter"} 2
+# HELP pull_secret_rotations_total Number of changes of the entries of the cluster pull secret observed since the exporter started
+# TYPE pull_secret_rotations_total counter
+pull_secret_rotations_total{_id="test-cluster-id",name="osd_exporter"} 1
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expectedResults),
+		"pull_secret_registries", "pull_secret_rotations_total")
+	require.NoError(t, err)
+	require.True(t, collector.changes.changes.LastChange.After(created))
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	require.Equal(t, "Normal PullSecretChanged Pull secret entries changed (rotation 1): 2 registries, "+
+		"added [registry.redhat.io], removed [], rotated [quay.io]", event)
+	require.NotContains(t, event, "dGVzdHVzZXI6")
+
+	// Reconciling an unchanged pull secret records nothing
+	reconcile()
+	require.Empty(t, recorder.Events)
+
+	require.NoError(t, fakeClient.Delete(context.TODO(), secret))
+	reconcile()
+	require.Equal(t, 0, testutil.CollectAndCount(collector, "pull_secret_rotations_total"))
+}
+
+func TestCollector_Sweep(t *testing.T) {
+	collector := NewCollector()
+	collector.SetPullSecretValid(testClusterId, true, ReasonValid)
+
+	expectedResults := `
+# HELP pull_secret_valid Indicates if the cluster pull secret is valid (1=valid, 0=invalid)
+# TYPE pull_secret_valid gauge
+pull_secret_valid{_id="test-cluster-id",name="osd_exporter",reason="Valid"} 1
+`
+	// A validity confirmed after expiredBefore is kept
+	collector.Sweep(time.Now().Add(-time.Hour))
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expectedResults), "pull_secret_valid")
+	require.NoError(t, err)
+
+	collector.Sweep(time.Now().Add(time.Hour))
+	require.Equal(t, 0, testutil.CollectAndCount(collector, "pull_secret_valid"))
+}
+
+func TestCollector_ValidTransition(t *testing.T) {
+	collector := NewCollector()
+	collector.SetPullSecretValid(testClusterId, false, ReasonMissingRegistry)
+	first, observed := collector.valid.LastTransition()
+	require.True(t, observed)
+
+	// A different reason for the same validity is no transition
+	collector.SetPullSecretValid(testClusterId, false, ReasonEmptyCredential)
+	unchanged, _ := collector.valid.LastTransition()
+	require.Equal(t, first, unchanged)
+
+	collector.SetPullSecretValid(testClusterId, true, ReasonValid)
+	changed, _ := collector.valid.LastTransition()
+	require.False(t, changed.Before(first))
+	require.True(t, collector.valid.Value())
+	require.Equal(t, 1, testutil.CollectAndCount(collector, "pull_secret_valid_last_transition_timestamp_seconds"))
+}
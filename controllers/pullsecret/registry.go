@@ -0,0 +1,170 @@
+/*
+Copyright 2026.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegistryPresence is whether the pull secret holds an entry for a registry of the policy
+type RegistryPresence struct {
+	// Registry is the glob pattern of the policy, e.g. "*.mirror.example.com"
+	Registry string
+	Required bool
+	Present  bool
+}
+
+// registryPolicy are the registries the pull secret is validated against. Registries are glob
+// patterns as understood by path.Match.
+type registryPolicy struct {
+	required []string
+	optional []string
+}
+
+// invalidPatterns returns the patterns of the policy which path.Match rejects
+func (p registryPolicy) invalidPatterns() []string {
+	var invalid []string
+	for _, pattern := range append(append([]string{}, p.required...), p.optional...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			invalid = append(invalid, pattern)
+		}
+	}
+	return invalid
+}
+
+// presence returns the presence of every registry of the policy in auths, required registries first
+func (p registryPolicy) presence(auths map[string]dockerConfigAuth) []RegistryPresence {
+	presence := make([]RegistryPresence, 0, len(p.required)+len(p.optional))
+	for _, pattern := range p.required {
+		presence = append(presence, RegistryPresence{
+			Registry: pattern,
+			Required: true,
+			Present:  len(matchingRegistries(pattern, auths)) > 0,
+		})
+	}
+	for _, pattern := range p.optional {
+		presence = append(presence, RegistryPresence{
+			Registry: pattern,
+			Present:  len(matchingRegistries(pattern, auths)) > 0,
+		})
+	}
+	return presence
+}
+
+// matchingRegistries returns the sorted registries of auths matching pattern. Invalid patterns only
+// match the registry of the same name.
+func matchingRegistries(pattern string, auths map[string]dockerConfigAuth) []string {
+	var registries []string
+	for registry := range auths {
+		matched, err := path.Match(pattern, registry)
+		if err != nil {
+			matched = pattern == registry
+		}
+		if matched {
+			registries = append(registries, registry)
+		}
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+// ParseRegistries returns the sorted registries of the pull secret holding a credential. Registries
+// are the keys of the auths of the .dockerconfigjson key, e.g. "quay.io" or "quay.io/org".
+func ParseRegistries(secret *corev1.Secret) ([]string, error) {
+	data, ok := secret.Data[dockerConfigJSONKey]
+	if !ok {
+		return nil, fmt.Errorf("pull secret has no %s key", dockerConfigJSONKey)
+	}
+	var config dockerConfigJSON
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse pull secret: %w", err)
+	}
+	registries := make([]string, 0, len(config.Auths))
+	for registry, auth := range config.Auths {
+		if auth.Auth != "" {
+			registries = append(registries, registry)
+		}
+	}
+	sort.Strings(registries)
+	return registries, nil
+}
+
+// PullSecretKey is the key of the cluster pull secret
+var PullSecretKey = types.NamespacedName{Namespace: pullSecretNamespace, Name: pullSecretName}
+
+// ReadRegistries returns the registries of the cluster pull secret holding a credential. A missing
+// pull secret holds none, as does one which cannot be parsed, which is reported by the pull secret
+// controller.
+func ReadRegistries(ctx context.Context, c client.Reader) ([]string, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, PullSecretKey, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	registries, err := ParseRegistries(secret)
+	if err != nil {
+		return nil, nil
+	}
+	return registries, nil
+}
+
+// CredentialFor returns the registry of registries whose credential container runtimes use to pull
+// from repository, e.g. "quay.io/org/image". The most specific registry whose host and path prefix
+// match repository wins. It returns false if none matches.
+func CredentialFor(registries []string, repository string) (string, bool) {
+	repository = normalizeRegistry(repository)
+	var match, matchName string
+	for _, registry := range registries {
+		name := normalizeRegistry(registry)
+		if name != repository && !strings.HasPrefix(repository, name+"/") {
+			continue
+		}
+		if len(name) > len(matchName) {
+			match, matchName = registry, name
+		}
+	}
+	return match, match != ""
+}
+
+// normalizeRegistry strips the scheme and the API version of a registry as found in pull secrets,
+// e.g. "https://index.docker.io/v1/", and names Docker Hub "docker.io"
+func normalizeRegistry(registry string) string {
+	if i := strings.Index(registry, "://"); i >= 0 {
+		registry = registry[i+3:]
+	}
+	registry = strings.TrimSuffix(registry, "/")
+	for _, suffix := range []string{"/v1", "/v2"} {
+		registry = strings.TrimSuffix(registry, suffix)
+	}
+	host, repository, _ := strings.Cut(registry, "/")
+	if dockerHubRegistries[host] {
+		host = "docker.io"
+	}
+	if repository == "" {
+		return host
+	}
+	return host + "/" + repository
+}
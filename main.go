@@ -17,8 +17,14 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -30,15 +36,22 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	customMetrics "github.com/openshift/operator-custom-metrics/pkg/metrics"
+	"github.com/openshift/osd-metrics-exporter/api/v1alpha1"
 	operatorConfig "github.com/openshift/osd-metrics-exporter/config"
+	"github.com/openshift/osd-metrics-exporter/controllers/certinventory"
 	"github.com/openshift/osd-metrics-exporter/controllers/clusterrole"
 	"github.com/openshift/osd-metrics-exporter/controllers/configmap"
 	"github.com/openshift/osd-metrics-exporter/controllers/cpms"
+	"github.com/openshift/osd-metrics-exporter/controllers/exporterconfig"
 	"github.com/openshift/osd-metrics-exporter/controllers/group"
+	"github.com/openshift/osd-metrics-exporter/controllers/imagemirror"
+	"github.com/openshift/osd-metrics-exporter/controllers/imagepull"
 	"github.com/openshift/osd-metrics-exporter/controllers/limited_support"
 	"github.com/openshift/osd-metrics-exporter/controllers/machine"
 	"github.com/openshift/osd-metrics-exporter/controllers/oauth"
@@ -49,11 +62,15 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 	routev1 "github.com/openshift/api/route/v1"
 	userv1 "github.com/openshift/api/user/v1"
 	promOperatorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -66,6 +83,7 @@ var (
 	scheme          = runtime.NewScheme()
 	setupLog        = ctrl.Log.WithName("setup")
 	metricsPort     = "8383"
+	metricsPath     = "/metrics"
 	watchNamespaces = map[string]cache.Config{
 		"openshift-osd-metrics": cache.Config{},
 		"openshift-config":      cache.Config{},
@@ -79,18 +97,23 @@ func init() {
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(configv1.Install(scheme))
 	utilruntime.Must(machinev1beta1.Install(scheme))
+	utilruntime.Must(operatorv1alpha1.Install(scheme))
 	utilruntime.Must(promOperatorv1.AddToScheme(scheme))
 	utilruntime.Must(rbacv1.AddToScheme(scheme))
 	utilruntime.Must(routev1.Install(scheme))
 	utilruntime.Must(userv1.Install(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var enableLeaderElection bool
 	var probeAddr string
+	var staleSeriesTTL time.Duration
 
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.DurationVar(&staleSeriesTTL, "stale-series-ttl", metrics.DefaultStaleSeriesTTL,
+		"How long per-object series are exposed without being re-asserted by their controller. 0 disables the expiry.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -99,9 +122,34 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig := ctrl.GetConfigOrDie()
+	// The MetricsExporterConfig CRD is optional, without it the exporter runs with the default configuration
+	hasExporterConfig, err := hasExporterConfigCrd(restConfig)
+	if err != nil {
+		setupLog.Error(err, "failed to discover the MetricsExporterConfig CRD")
+		os.Exit(1)
+	}
+	setupLog.Info("retrieving exporter configuration")
+	exporterConfig, err := getExporterConfig(restConfig, hasExporterConfig)
+	if err != nil {
+		setupLog.Error(err, "Failed to retrieve exporter configuration")
+		os.Exit(1)
+	}
+	namespaces := watchNamespaces
+	if len(exporterConfig.Spec.WatchNamespaces) > 0 {
+		namespaces = map[string]cache.Config{}
+		for _, ns := range exporterConfig.Spec.WatchNamespaces {
+			namespaces[ns] = cache.Config{}
+		}
+	}
+	port := metricsPort
+	if exporterConfig.Spec.MetricsPort != nil {
+		port = strconv.Itoa(int(*exporterConfig.Spec.MetricsPort))
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
-		// Disable metrics serving
+		// Disable metrics serving, controller-runtime metrics are served by the exporter's metrics endpoint
 		Metrics: metricsserver.Options{BindAddress: "0"},
 		//Port:    9443,
 		WebhookServer:          webhook.NewServer(webhook.Options{Port: 9443}),
@@ -109,13 +157,32 @@ func main() {
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "osd-metrics-exporter-lock",
 		Cache: cache.Options{
-			DefaultNamespaces: watchNamespaces,
+			DefaultNamespaces: namespaces,
 			ByObject: map[client.Object]cache.ByObject{
 				&corev1.Secret{}: {
 					Namespaces: map[string]cache.Config{
 						"openshift-config": {},
 					},
 				},
+				// Pods failing to pull images are looked for cluster wide. Only pending pods, which have not
+				// started every container yet, are cached, and only their container statuses.
+				&corev1.Pod{}: {
+					Namespaces: map[string]cache.Config{
+						cache.AllNamespaces: {
+							FieldSelector: fields.OneTermEqualSelector("status.phase", string(corev1.PodPending)),
+						},
+					},
+					Transform: imagepull.TrimPod,
+				},
+				// Events of every namespace but openshift-machine-api are only cached for pull failures
+				&corev1.Event{}: {
+					Namespaces: map[string]cache.Config{
+						"openshift-machine-api": {},
+						cache.AllNamespaces: {
+							FieldSelector: fields.OneTermEqualSelector("reason", "Failed"),
+						},
+					},
+				},
 			},
 		},
 	})
@@ -131,6 +198,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	registry := metrics.NewRegistry(metrics.AggregatorResyncInterval)
+	if err := registry.RegisterControllerMetrics(ctrlmetrics.Registry); err != nil {
+		setupLog.Error(err, "unable to register controller metrics")
+		os.Exit(1)
+	}
+
 	if err = (&clusterrole.ClusterRoleReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -139,70 +212,130 @@ func main() {
 		os.Exit(1)
 	}
 
+	configMapCollector := configmap.NewCollector()
+	registry.MustRegister(configMapCollector)
 	if err = (&configmap.ConfigMapReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   configMapCollector,
+		ClusterId: clusterId,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Configmap")
 		os.Exit(1)
 	}
 
+	certInventoryCollector := certinventory.NewCollector()
+	registry.MustRegister(certInventoryCollector)
+	if err = (&certinventory.CertificateInventoryReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   certInventoryCollector,
+		ClusterId: clusterId,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CertificateInventory")
+		os.Exit(1)
+	}
+
+	groupCollector := group.NewCollector(clusterId)
+	registry.MustRegister(groupCollector)
 	if err = (&group.GroupReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   groupCollector,
+		ClusterId: clusterId,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Group")
 		os.Exit(1)
 	}
 
+	// Mirror sets are only watched for the kinds served by the cluster, which depend on its version
+	mirrorSetKinds, err := servedMirrorSetKinds(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "failed to discover the served mirror set kinds")
+	}
+	imageMirrorCollector := imagemirror.NewCollector()
+	registry.MustRegister(imageMirrorCollector)
+	if err = (&imagemirror.ImageMirrorReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   imageMirrorCollector,
+		ClusterId: clusterId,
+		Kinds:     mirrorSetKinds,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ImageMirror")
+		os.Exit(1)
+	}
+
+	imagePullCollector := imagepull.NewCollector()
+	registry.MustRegister(imagePullCollector)
+	if err = (&imagepull.ImagePullReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   imagePullCollector,
+		ClusterId: clusterId,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ImagePull")
+		os.Exit(1)
+	}
+
+	limitedSupportCollector := limited_support.NewCollector(clusterId)
+	registry.MustRegister(limitedSupportCollector)
 	if err = (&limited_support.LimitedSupportConfigMapReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   limitedSupportCollector,
+		ClusterId: clusterId,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Limited Support")
 		os.Exit(1)
 	}
 
-	if err = (&machine.MachineReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-	}).SetupWithManager(mgr); err != nil {
+	machineCollector := machine.NewCollector()
+	registry.MustRegister(machineCollector)
+	machineReconciler := &machine.MachineReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Metrics: machineCollector,
+	}
+	if err = machineReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Machine")
 		os.Exit(1)
 	}
 
+	oauthCollector := oauth.NewCollector()
+	registry.MustRegister(oauthCollector)
 	if err = (&oauth.OAuthReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Metrics: oauthCollector,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OAuth")
 		os.Exit(1)
 	}
 
+	proxyCollector := proxy.NewCollector()
+	registry.MustRegister(proxyCollector)
 	if err = (&proxy.ProxyReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   proxyCollector,
+		ClusterId: clusterId,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Proxy")
 		os.Exit(1)
 	}
 
-	if err = (&pullsecret.PullSecretReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-		ClusterId:         clusterId,
-	}).SetupWithManager(mgr); err != nil {
+	pullSecretCollector := pullsecret.NewCollector()
+	registry.MustRegister(pullSecretCollector)
+	pullSecretReconciler := &pullsecret.PullSecretReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   pullSecretCollector,
+		ClusterId: clusterId,
+		Prober:    pullsecret.NewProber(),
+		Recorder:  mgr.GetEventRecorderFor("osd-metrics-exporter"),
+	}
+	if err = pullSecretReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PullSecret")
 		os.Exit(1)
 	}
@@ -216,11 +349,13 @@ func main() {
 	// and clusters without cpms installed, we check if the cpms CRD is installed
 	// before creating the controller
 	if hasCPMS {
+		cpmsCollector := cpms.NewCollector()
+		registry.MustRegister(cpmsCollector)
 		if err = (&cpms.CPMSReconciler{
-			Client:            mgr.GetClient(),
-			Scheme:            mgr.GetScheme(),
-			MetricsAggregator: metrics.GetMetricsAggregator(clusterId),
-			ClusterId:         clusterId,
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			Metrics:   cpmsCollector,
+			ClusterId: clusterId,
 		}).SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "CPMS")
 			os.Exit(1)
@@ -229,6 +364,28 @@ func main() {
 		setupLog.Info("ControlPlaneMachineSet CRD not found, skipping cpms controller setup")
 	}
 
+	// Every collector is registered and every controller is set up regardless of the configuration,
+	// so that collectors can be enabled and disabled at runtime.
+	exporterConfigReconciler := &exporterconfig.ExporterConfigReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Registry:              registry,
+		Configurables:         []exporterconfig.Configurable{machineReconciler, pullSecretReconciler},
+		DefaultStaleSeriesTTL: staleSeriesTTL,
+	}
+	if err = exporterConfigReconciler.Apply(exporterConfig); err != nil {
+		setupLog.Error(err, "Failed to apply exporter configuration")
+		os.Exit(1)
+	}
+	if hasExporterConfig {
+		if err = exporterConfigReconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "MetricsExporterConfig")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("MetricsExporterConfig CRD not found, skipping exporter config controller setup")
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -238,18 +395,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Setup metrics collector
-	collector := metrics.GetMetricsAggregator(clusterId)
-	done := collector.Run()
+	// Setup metrics collectors
+	done := registry.Run()
 	defer close(done)
-	metricsConfig := customMetrics.NewBuilder(operatorConfig.OperatorNamespace, operatorConfig.OperatorName).
-		WithPath("/metrics").
-		WithPort(metricsPort).
-		WithServiceMonitor().
-		WithCollectors(metrics.GetMetricsAggregator(clusterId).GetMetrics()).
-		GetConfig()
-	if err = customMetrics.ConfigureMetrics(context.TODO(), *metricsConfig); err != nil {
-		setupLog.Error(err, "Failed to run metrics server")
+	if err = serveMetrics(mgr, port, registry.Gatherer()); err != nil {
+		setupLog.Error(err, "Failed to set up metrics server")
 		os.Exit(1)
 	}
 
@@ -273,6 +423,103 @@ func getClusterID(client client.Reader) (string, error) {
 	return string(cv.Spec.ClusterID), nil
 }
 
+// getExporterConfig returns the MetricsExporterConfig, or an empty configuration keeping
+// all defaults if there is none or the CRD is not installed
+func getExporterConfig(config *rest.Config, crdInstalled bool) (*v1alpha1.MetricsExporterConfig, error) {
+	if !crdInstalled {
+		return &v1alpha1.MetricsExporterConfig{}, nil
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	exporterConfig := &v1alpha1.MetricsExporterConfig{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: v1alpha1.MetricsExporterConfigName}, exporterConfig)
+	if apierrors.IsNotFound(err) {
+		return &v1alpha1.MetricsExporterConfig{}, nil
+	}
+	return exporterConfig, err
+}
+
+// hasExporterConfigCrd returns whether the MetricsExporterConfig CRD is installed
+func hasExporterConfigCrd(config *rest.Config) (bool, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return false, err
+	}
+	return discovery.IsResourceEnabled(client, v1alpha1.GroupVersion.WithResource("metricsexporterconfigs"))
+}
+
+// serveMetrics adds a runnable to mgr which creates or updates the Service and ServiceMonitor scraping port,
+// and serves gatherer on port until the manager stops.
+func serveMetrics(mgr ctrl.Manager, port string, gatherer prometheus.Gatherer) error {
+	servicePort, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		defer listener.Close() // nolint:errcheck
+		if err := ensureMetricsService(ctx, mgr.GetConfig(), int32(servicePort)); err != nil {
+			return err
+		}
+		shutdownDone := make(chan struct{})
+		go func() {
+			defer close(shutdownDone)
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				setupLog.Error(err, "Failed to shut down metrics server")
+			}
+		}()
+		setupLog.Info("Serving metrics", "port", port, "path", metricsPath)
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		<-shutdownDone
+		return nil
+	}))
+}
+
+// ensureMetricsService creates or updates the Service exposing servicePort and the ServiceMonitor scraping it
+func ensureMetricsService(ctx context.Context, config *rest.Config, servicePort int32) error {
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	service, err := customMetrics.GenerateService(servicePort, metricsPath,
+		operatorConfig.OperatorName, operatorConfig.OperatorNamespace, nil)
+	if err != nil {
+		return err
+	}
+	desiredService := service.DeepCopy()
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, service, func() error {
+		// The cluster IP of an existing service is kept
+		service.Labels = desiredService.Labels
+		service.Spec.Ports = desiredService.Spec.Ports
+		service.Spec.Selector = desiredService.Spec.Selector
+		return nil
+	}); err != nil {
+		return err
+	}
+	serviceMonitor := customMetrics.GenerateServiceMonitor(desiredService)
+	desiredServiceMonitor := serviceMonitor.DeepCopy()
+	_, err = controllerutil.CreateOrUpdate(ctx, c, serviceMonitor, func() error {
+		serviceMonitor.Labels = desiredServiceMonitor.Labels
+		serviceMonitor.Spec = desiredServiceMonitor.Spec
+		return nil
+	})
+	return err
+}
+
 func hasCpmsCrd(config *rest.Config) (bool, error) {
 	client, err := discovery.NewDiscoveryClientForConfig(config)
 	if err != nil {
@@ -285,3 +532,22 @@ func hasCpmsCrd(config *rest.Config) (bool, error) {
 	}
 	return discovery.IsResourceEnabled(client, cpmsGVR)
 }
+
+// servedMirrorSetKinds returns the kinds of mirror sets served by the cluster
+func servedMirrorSetKinds(config *rest.Config) ([]imagemirror.Kind, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	var kinds []imagemirror.Kind
+	for _, kind := range imagemirror.Kinds {
+		served, err := discovery.IsResourceEnabled(client, kind.Resource())
+		if err != nil {
+			return kinds, err
+		}
+		if served {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collectorLabel  = "collector"
+	controllerLabel = "controller"
+)
+
+var (
+	collectorSeriesDesc = NewDesc(
+		"osd_exporter_collector_series",
+		"Number of series exposed by a collector during the last aggregation loop",
+		collectorLabel,
+	)
+	aggregationDurationDesc = NewDesc(
+		"osd_exporter_aggregation_duration_seconds",
+		"Duration of the last aggregation loop",
+	)
+	lastSuccessfulReconcileDesc = NewDesc(
+		"osd_exporter_last_successful_reconcile_timestamp_seconds",
+		"Unix timestamp of the last successful reconcile of the controller feeding a collector",
+		controllerLabel,
+	)
+)
+
+// exporterCollector exposes metrics about the exporter itself, updated by the aggregation loop of the Registry.
+// The last successful reconciles are read from the status of the collectors at scrape time.
+type exporterCollector struct {
+	aggregationDuration time.Duration
+	collectorSeries     map[string]int
+	status              func() []CollectorStatus
+	mutex               sync.Mutex
+}
+
+func newExporterCollector(status func() []CollectorStatus) *exporterCollector {
+	return &exporterCollector{
+		collectorSeries: map[string]int{},
+		status:          status,
+	}
+}
+
+func (c *exporterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collectorSeriesDesc
+	ch <- aggregationDurationDesc
+	ch <- lastSuccessfulReconcileDesc
+}
+
+func (c *exporterCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range c.status() {
+		if !status.LastSuccessTime.IsZero() {
+			ch <- NewGauge(lastSuccessfulReconcileDesc, float64(status.LastSuccessTime.Unix()), status.Name)
+		}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for collector, series := range c.collectorSeries {
+		ch <- NewGauge(collectorSeriesDesc, float64(series), collector)
+	}
+	ch <- NewGauge(aggregationDurationDesc, c.aggregationDuration.Seconds())
+}
+
+func (c *exporterCollector) setAggregation(duration time.Duration, collectorSeries map[string]int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.aggregationDuration = duration
+	c.collectorSeries = collectorSeries
+}
+
+// countSeries returns the number of series currently emitted by collector
+func countSeries(collector prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	count := 0
+	for range ch {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRegistry_ControllerMetrics(t *testing.T) {
+	controllerRegistry := prometheus.NewRegistry()
+	reconcileTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_reconcile_total",
+		Help: "Total number of reconciliations per controller",
+	}, []string{controllerLabel, "result"})
+	controllerRegistry.MustRegister(reconcileTotal)
+
+	registry := NewRegistry(time.Minute)
+	require.NoError(t, registry.RegisterControllerMetrics(controllerRegistry))
+	require.Error(t, registry.RegisterControllerMetrics(controllerRegistry))
+
+	reconcileTotal.WithLabelValues("secret", "success").Inc()
+	reconcileTotal.WithLabelValues("proxy", "error").Inc()
+
+	expectedReconciles := `
+# HELP controller_runtime_reconcile_total Total number of reconciliations per controller
+# TYPE controller_runtime_reconcile_total counter
+controller_runtime_reconcile_total{controller="proxy",result="error"} 1
+controller_runtime_reconcile_total{controller="secret",result="success"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry.Gatherer(), strings.NewReader(expectedReconciles),
+		"controller_runtime_reconcile_total"))
+	// The prometheus registry itself only holds the collectors and the exporter metrics
+	count, err := testutil.GatherAndCount(registry.GetPrometheusRegistry(), "controller_runtime_reconcile_total")
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestRegistry_LastSuccessfulReconcile(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	collector := &statusCollector{testCollector: testCollector{name: "secret", desc: NewDesc("secret", "test gauge")}}
+	registry.MustRegister(collector, newTestCollector("plain"))
+	var reconcileErr error
+	reconciler := NewReconciler(collector, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, reconcileErr
+	}))
+	const metric = "osd_exporter_last_successful_reconcile_timestamp_seconds"
+
+	// Nothing is reported before the first successful reconcile
+	count, err := testutil.GatherAndCount(registry.GetPrometheusRegistry(), metric)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	reconcileErr = fmt.Errorf("failed")
+	_, _ = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	count, err = testutil.GatherAndCount(registry.GetPrometheusRegistry(), metric)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	reconcileErr = nil
+	before := time.Now().Truncate(time.Second)
+	_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	require.NoError(t, err)
+	lastSuccess := collector.LastSuccess()
+	require.False(t, lastSuccess.Before(before))
+	expected := fmt.Sprintf(`
+# HELP osd_exporter_last_successful_reconcile_timestamp_seconds Unix timestamp of the last successful reconcile of the controller feeding a collector
+# TYPE osd_exporter_last_successful_reconcile_timestamp_seconds gauge
+osd_exporter_last_successful_reconcile_timestamp_seconds{controller="secret",name="osd_exporter"} %d
+`, lastSuccess.Unix())
+	require.NoError(t, testutil.GatherAndCompare(registry.GetPrometheusRegistry(), strings.NewReader(expected), metric))
+
+	// Requests postponed while the collector is disabled are not successful reconciles
+	require.NoError(t, registry.SetEnabled("secret", false))
+	_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	require.NoError(t, err)
+	require.Equal(t, lastSuccess, collector.LastSuccess())
+}
+
+func TestRegistry_CollectorSeries(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	registry.MustRegister(newTestCollector("first"), newTestCollector("second"))
+	registry.aggregate(time.Now())
+
+	expected := `
+# HELP osd_exporter_collector_series Number of series exposed by a collector during the last aggregation loop
+# TYPE osd_exporter_collector_series gauge
+osd_exporter_collector_series{collector="first",name="osd_exporter"} 1
+osd_exporter_collector_series{collector="second",name="osd_exporter"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry.GetPrometheusRegistry(), strings.NewReader(expected), "osd_exporter_collector_series"))
+	require.Equal(t, 1, testutil.CollectAndCount(registry.exporter, "osd_exporter_aggregation_duration_seconds"))
+}
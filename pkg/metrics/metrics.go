@@ -1,18 +1,48 @@
 package metrics
 
-import "time"
+import (
+	"time"
 
-const (
-	aggregatorResyncInterval = time.Minute
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-var (
-	aggregator *AdoptionMetricsAggregator
+const (
+	AggregatorResyncInterval = time.Minute
+
+	// DefaultStaleSeriesTTL is how long per-object series stay exposed without
+	// being re-asserted by their reconciler
+	DefaultStaleSeriesTTL = time.Hour
+
+	// DisabledRequeueInterval is how long the requests of a disabled reconciler are postponed
+	DisabledRequeueInterval = time.Minute
+
+	// ClusterIDLabel is the label carrying the cluster id on cluster scoped metrics
+	ClusterIDLabel = "_id"
+
+	nameLabel        = "name"
+	osdExporterValue = "osd_exporter"
 )
 
-func GetMetricsAggregator(clusterId string) *AdoptionMetricsAggregator {
-	if aggregator == nil {
-		aggregator = NewMetricsAggregator(aggregatorResyncInterval, clusterId)
+// NewDesc returns the description of a metric carrying the name="osd_exporter" label
+// shared by most metrics of the exporter.
+func NewDesc(name, help string, variableLabels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(name, help, variableLabels, prometheus.Labels{nameLabel: osdExporterValue})
+}
+
+// NewGauge creates a gauge sample for desc, to be emitted from a collector's Collect
+func NewGauge(desc *prometheus.Desc, value float64, labelValues ...string) prometheus.Metric {
+	return prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labelValues...)
+}
+
+// NewCounter creates a counter sample for desc, to be emitted from a collector's Collect
+func NewCounter(desc *prometheus.Desc, value float64, labelValues ...string) prometheus.Metric {
+	return prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, labelValues...)
+}
+
+// BoolToFloat64 converts a boolean signal to its 0/1 gauge value
+func BoolToFloat64(b bool) float64 {
+	if b {
+		return 1
 	}
-	return aggregator
+	return 0
 }
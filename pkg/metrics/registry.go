@@ -0,0 +1,276 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("metrics")
+
+// Collector is implemented by every controller package exposing metrics. Each
+// collector owns the metrics and the state of a single domain and describes
+// itself to prometheus.
+type Collector interface {
+	prometheus.Collector
+	// Name uniquely identifies the collector within a Registry, usually the name of the owning controller
+	Name() string
+}
+
+// Sweeper is implemented by collectors exposing per-object series which have to be
+// re-asserted by their reconciler. Series not confirmed since expiredBefore are dropped,
+// so that a missed delete event does not leave a series behind forever.
+type Sweeper interface {
+	Sweep(expiredBefore time.Time)
+}
+
+// Resetter is implemented by collectors which can drop all of their state, e.g. when they get disabled
+type Resetter interface {
+	Reset()
+}
+
+// Resyncer is implemented by collectors whose controller re-reconciles its resources on demand.
+// Collectors are resynced after every aggregation loop and whenever they get enabled, so that state
+// depending on the wall clock, e.g. the expiry of a certificate, is re-evaluated without any watch event.
+type Resyncer interface {
+	Resync()
+}
+
+// Registry holds the collectors of all controllers and periodically sweeps their stale series.
+// Every registry is backed by its own prometheus registry, so that independent
+// registries never share metrics.
+type Registry struct {
+	collectors []Collector
+	// disabled holds the names of the collectors which are registered but not exposed
+	disabled            map[string]bool
+	prometheusRegistry  *prometheus.Registry
+	mutex               sync.Mutex
+	aggregationInterval time.Duration
+	// intervalChanged wakes up the aggregation loop when the aggregation interval changes
+	intervalChanged chan struct{}
+	staleSeriesTTL  time.Duration
+	exporter        *exporterCollector
+	// controllerMetrics is the controller-runtime registry served together with the prometheus registry, if any
+	controllerMetrics prometheus.Gatherer
+}
+
+// NewRegistry creates an empty registry sweeping its collectors every aggregationInterval.
+// Series are dropped once they have not been confirmed for DefaultStaleSeriesTTL.
+// The registry always exposes metrics about the exporter itself.
+func NewRegistry(aggregationInterval time.Duration) *Registry {
+	r := &Registry{
+		disabled:            map[string]bool{},
+		prometheusRegistry:  prometheus.NewRegistry(),
+		aggregationInterval: aggregationInterval,
+		intervalChanged:     make(chan struct{}, 1),
+		staleSeriesTTL:      DefaultStaleSeriesTTL,
+	}
+	r.exporter = newExporterCollector(r.Status)
+	r.prometheusRegistry.MustRegister(r.exporter)
+	return r
+}
+
+// RegisterControllerMetrics serves the metrics gathered from the controller-runtime registry
+// together with the prometheus registry.
+func (r *Registry) RegisterControllerMetrics(gatherer prometheus.Gatherer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.controllerMetrics != nil {
+		return fmt.Errorf("controller metrics are already registered")
+	}
+	r.controllerMetrics = gatherer
+	return nil
+}
+
+// SetAggregationInterval changes the interval of the aggregation loop, also while it is running
+func (r *Registry) SetAggregationInterval(interval time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if interval == r.aggregationInterval {
+		return
+	}
+	r.aggregationInterval = interval
+	select {
+	case r.intervalChanged <- struct{}{}:
+	default:
+	}
+}
+
+// SetEnabled enables or disables the registered collector with the given name. Disabled collectors are
+// removed from the prometheus registry, drop their state and the requests of their reconciler are postponed.
+func (r *Registry) SetEnabled(name string, enabled bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var collector Collector
+	for _, c := range r.collectors {
+		if c.Name() == name {
+			collector = c
+		}
+	}
+	if collector == nil {
+		return fmt.Errorf("collector %s is not registered", name)
+	}
+	if r.disabled[name] == !enabled {
+		return nil
+	}
+
+	reporter, isReporter := collector.(StatusReporter)
+	if enabled {
+		if err := r.prometheusRegistry.Register(collector); err != nil {
+			return fmt.Errorf("failed to register collector %s: %w", name, err)
+		}
+		if isReporter {
+			reporter.SetEnabled(true)
+		}
+		delete(r.disabled, name)
+		// The state of the collector was dropped when it got disabled
+		if resyncer, ok := collector.(Resyncer); ok {
+			resyncer.Resync()
+		}
+		return nil
+	}
+	if isReporter {
+		reporter.SetEnabled(false)
+	}
+	r.prometheusRegistry.Unregister(collector)
+	if resetter, ok := collector.(Resetter); ok {
+		resetter.Reset()
+	}
+	r.disabled[name] = true
+	return nil
+}
+
+// SetStaleSeriesTTL sets how long series stay exposed without being confirmed.
+// A ttl of 0 disables the sweeping of stale series.
+func (r *Registry) SetStaleSeriesTTL(ttl time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.staleSeriesTTL = ttl
+}
+
+// Register adds a collector to the registry. Collector names must be unique.
+func (r *Registry) Register(collector Collector) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, c := range r.collectors {
+		if c.Name() == collector.Name() {
+			return fmt.Errorf("collector %s is already registered", collector.Name())
+		}
+	}
+	if err := r.prometheusRegistry.Register(collector); err != nil {
+		return fmt.Errorf("failed to register collector %s: %w", collector.Name(), err)
+	}
+	r.collectors = append(r.collectors, collector)
+	return nil
+}
+
+// MustRegister registers the collectors and panics on error
+func (r *Registry) MustRegister(collectors ...Collector) {
+	for _, c := range collectors {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// GetCollectors returns the registered collectors in registration order
+func (r *Registry) GetCollectors() []prometheus.Collector {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	collectors := make([]prometheus.Collector, len(r.collectors))
+	for i, c := range r.collectors {
+		collectors[i] = c
+	}
+	return collectors
+}
+
+// GetPrometheusRegistry returns the prometheus registry all collectors are registered with
+func (r *Registry) GetPrometheusRegistry() *prometheus.Registry {
+	return r.prometheusRegistry
+}
+
+// Gatherer returns the gatherer of the prometheus registry and of the controller-runtime metrics,
+// if registered. It is meant to be handed to the metrics server.
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.controllerMetrics == nil {
+		return r.prometheusRegistry
+	}
+	return prometheus.Gatherers{r.prometheusRegistry, r.controllerMetrics}
+}
+
+func (r *Registry) Run() chan interface{} {
+	ticker := time.NewTicker(r.getAggregationInterval())
+	done := make(chan interface{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-r.intervalChanged:
+				ticker.Reset(r.getAggregationInterval())
+			case now := <-ticker.C:
+				r.aggregate(now)
+			}
+		}
+	}()
+	return done
+}
+
+func (r *Registry) getAggregationInterval() time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.aggregationInterval
+}
+
+// enabledCollectors returns the enabled collectors in registration order
+func (r *Registry) enabledCollectors() []Collector {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var collectors []Collector
+	for _, c := range r.collectors {
+		if !r.disabled[c.Name()] {
+			collectors = append(collectors, c)
+		}
+	}
+	return collectors
+}
+
+// aggregate runs a single iteration of the aggregation loop
+func (r *Registry) aggregate(now time.Time) {
+	start := time.Now()
+	r.sweep(now)
+
+	collectors := r.enabledCollectors()
+	for _, c := range collectors {
+		if resyncer, ok := c.(Resyncer); ok {
+			resyncer.Resync()
+		}
+	}
+	collectorSeries := make(map[string]int, len(collectors))
+	for _, c := range collectors {
+		collectorSeries[c.Name()] = countSeries(c)
+	}
+	r.exporter.setAggregation(time.Since(start), collectorSeries)
+}
+
+func (r *Registry) sweep(now time.Time) {
+	collectors := r.enabledCollectors()
+	r.mutex.Lock()
+	ttl := r.staleSeriesTTL
+	r.mutex.Unlock()
+
+	if ttl <= 0 {
+		return
+	}
+	for _, c := range collectors {
+		if s, ok := c.(Sweeper); ok {
+			s.Sweep(now.Add(-ttl))
+		}
+	}
+}
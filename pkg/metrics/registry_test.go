@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testCollector struct {
+	name  string
+	desc  *prometheus.Desc
+	value atomic.Int64
+	// expiredBefore is the unix nano timestamp of the last sweep
+	expiredBefore atomic.Int64
+}
+
+func newTestCollector(name string) *testCollector {
+	return &testCollector{
+		name: name,
+		desc: NewDesc(name, "test gauge"),
+	}
+}
+
+func (c *testCollector) Name() string                        { return c.name }
+func (c *testCollector) Describe(ch chan<- *prometheus.Desc) { ch <- c.desc }
+func (c *testCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- NewGauge(c.desc, float64(c.value.Load()))
+}
+func (c *testCollector) Sweep(expiredBefore time.Time) {
+	c.expiredBefore.Store(expiredBefore.UnixNano())
+}
+func (c *testCollector) Reset() {
+	c.value.Store(0)
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	require.NoError(t, registry.Register(newTestCollector("first")))
+	require.NoError(t, registry.Register(newTestCollector("second")))
+	require.Error(t, registry.Register(newTestCollector("first")))
+
+	collectors := registry.GetCollectors()
+	require.Len(t, collectors, 2)
+
+	expected := `
+# HELP first test gauge
+# TYPE first gauge
+first{name="osd_exporter"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(collectors[0], strings.NewReader(expected)))
+}
+
+func TestRegistry_Run(t *testing.T) {
+	registry := NewRegistry(10 * time.Millisecond)
+	collector := newTestCollector("test")
+	registry.MustRegister(collector)
+
+	registry.SetStaleSeriesTTL(time.Hour)
+
+	start := time.Now()
+	done := registry.Run()
+	defer close(done)
+	require.Eventually(t, func() bool {
+		return collector.expiredBefore.Load() > 0
+	}, time.Second, 10*time.Millisecond)
+	expiredBefore := time.Unix(0, collector.expiredBefore.Load())
+	require.WithinDuration(t, start.Add(-time.Hour), expiredBefore, time.Second)
+}
+
+func TestRegistry_SetAggregationInterval(t *testing.T) {
+	registry := NewRegistry(time.Hour)
+	collector := newTestCollector("test")
+	registry.MustRegister(collector)
+
+	done := registry.Run()
+	defer close(done)
+	registry.SetAggregationInterval(10 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		return collector.expiredBefore.Load() > 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRegistry_SetEnabled(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	collector := &statusCollector{testCollector: testCollector{name: "test", desc: NewDesc("test", "test gauge")}}
+	registry.MustRegister(collector)
+	collector.value.Store(1)
+
+	require.Error(t, registry.SetEnabled("unknown", false))
+
+	// Disabled collectors are not exposed, not swept and drop their state
+	require.NoError(t, registry.SetEnabled("test", false))
+	require.NoError(t, registry.SetEnabled("test", false))
+	count, err := testutil.GatherAndCount(registry.GetPrometheusRegistry(), "test")
+	require.NoError(t, err)
+	require.Zero(t, count)
+	require.Zero(t, collector.value.Load())
+	require.False(t, collector.Enabled())
+	require.False(t, registry.Status()[0].Active)
+	registry.sweep(time.Now())
+	require.Zero(t, collector.expiredBefore.Load())
+
+	require.NoError(t, registry.SetEnabled("test", true))
+	expected := `
+# HELP test test gauge
+# TYPE test gauge
+test{name="osd_exporter"} 0
+`
+	require.NoError(t, testutil.GatherAndCompare(registry.GetPrometheusRegistry(), strings.NewReader(expected), "test"))
+	require.True(t, collector.Enabled())
+	require.True(t, registry.Status()[0].Active)
+}
+
+func TestRegistry_SweepDisabled(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	collector := newTestCollector("test")
+	registry.MustRegister(collector)
+	registry.SetStaleSeriesTTL(0)
+
+	registry.sweep(time.Now())
+	require.Zero(t, collector.expiredBefore.Load())
+}
+
+func TestRegistry_Independent(t *testing.T) {
+	first := NewRegistry(time.Minute)
+	second := NewRegistry(time.Minute)
+	firstCollector := newTestCollector("test")
+	secondCollector := newTestCollector("test")
+	first.MustRegister(firstCollector)
+	second.MustRegister(secondCollector)
+
+	firstCollector.value.Store(1)
+	secondCollector.value.Store(2)
+
+	expected := `
+# HELP test test gauge
+# TYPE test gauge
+test{name="osd_exporter"} %s
+`
+	require.NoError(t, testutil.GatherAndCompare(first.GetPrometheusRegistry(), strings.NewReader(fmt.Sprintf(expected, "1")), "test"))
+	require.NoError(t, testutil.GatherAndCompare(second.GetPrometheusRegistry(), strings.NewReader(fmt.Sprintf(expected, "2")), "test"))
+}
+
+func TestRegistry_RegisterConflictingMetrics(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	registry.MustRegister(newTestCollector("test"))
+
+	// A different collector exposing an already registered metric is rejected
+	conflicting := newTestCollector("conflicting")
+	conflicting.desc = NewDesc("test", "test gauge")
+	require.Error(t, registry.Register(conflicting))
+	require.Len(t, registry.GetCollectors(), 1)
+}
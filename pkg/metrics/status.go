@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// StatusReporter is implemented by collectors keeping track of the reconciler feeding them,
+// usually by embedding a ReconcileStatus
+type StatusReporter interface {
+	RecordError(err error)
+	LastError() (string, time.Time)
+	RecordSuccess()
+	LastSuccess() time.Time
+	SetEnabled(enabled bool)
+	Enabled() bool
+}
+
+// ReconcileStatus keeps whether the reconciler feeding a collector is enabled, its last error and
+// last success, and resyncs the resources of the reconciler. It is meant to be embedded into collectors.
+type ReconcileStatus struct {
+	disabled        bool
+	lastError       string
+	lastErrorTime   time.Time
+	lastSuccessTime time.Time
+	// resyncObjects are the objects an event is emitted for on resyncEvents by every resync
+	resyncObjects []client.Object
+	resyncEvents  chan event.GenericEvent
+	mutex         sync.Mutex
+}
+
+var (
+	_ StatusReporter = &ReconcileStatus{}
+	_ Resyncer       = &ReconcileStatus{}
+)
+
+// RecordError records err as the last error, nil errors are ignored
+func (s *ReconcileStatus) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastError = err.Error()
+	s.lastErrorTime = time.Now()
+}
+
+// LastError returns the last recorded error and when it occurred, an empty string if there was none
+func (s *ReconcileStatus) LastError() (string, time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastError, s.lastErrorTime
+}
+
+// RecordSuccess records now as the time of the last successful reconcile
+func (s *ReconcileStatus) RecordSuccess() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastSuccessTime = time.Now()
+}
+
+// LastSuccess returns when the last successful reconcile finished, the zero time if there was none
+func (s *ReconcileStatus) LastSuccess() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastSuccessTime
+}
+
+// SetEnabled enables or disables the reconciler
+func (s *ReconcileStatus) SetEnabled(enabled bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.disabled = !enabled
+}
+
+// Enabled returns whether the reconciler is enabled, reconcilers are enabled unless disabled explicitly
+func (s *ReconcileStatus) Enabled() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return !s.disabled
+}
+
+// ResyncSource returns the source of the resyncs of the reconciler, emitting a GenericEvent for each of
+// objects on every resync. It is meant to be watched by the controller of the reconciler, for singleton
+// resources whose name is known upfront, and to be called once.
+func (s *ReconcileStatus) ResyncSource(objects ...client.Object) source.Source {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.resyncObjects = objects
+	s.resyncEvents = make(chan event.GenericEvent, len(objects))
+	return source.Channel(s.resyncEvents, &handler.EnqueueRequestForObject{})
+}
+
+// Resync emits an event for every resynced object. Events are dropped while the previous
+// resync is still pending.
+func (s *ReconcileStatus) Resync() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, object := range s.resyncObjects {
+		select {
+		case s.resyncEvents <- event.GenericEvent{Object: object}:
+		default:
+		}
+	}
+}
+
+// NewReconciler wraps reconciler so that every error it returns and every successful reconcile is
+// recorded on status. Requests are requeued after DisabledRequeueInterval while status is disabled,
+// so that no request, including pending requeues of the reconciler, is lost once it is enabled again.
+func NewReconciler(status StatusReporter, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		if !status.Enabled() {
+			return reconcile.Result{RequeueAfter: DisabledRequeueInterval}, nil
+		}
+		result, err := reconciler.Reconcile(ctx, req)
+		if err != nil {
+			status.RecordError(err)
+		} else {
+			status.RecordSuccess()
+		}
+		return result, err
+	})
+}
+
+// CollectorStatus is the state of a registered collector
+type CollectorStatus struct {
+	Name            string
+	Active          bool
+	LastError       string
+	LastErrorTime   time.Time
+	LastSuccessTime time.Time
+}
+
+// Status returns the state of every registered collector in registration order
+func (r *Registry) Status() []CollectorStatus {
+	r.mutex.Lock()
+	collectors := make([]Collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	status := make([]CollectorStatus, len(collectors))
+	for i, c := range collectors {
+		status[i].Active = !r.disabled[c.Name()]
+	}
+	r.mutex.Unlock()
+
+	for i, c := range collectors {
+		status[i].Name = c.Name()
+		if reporter, ok := c.(StatusReporter); ok {
+			status[i].LastError, status[i].LastErrorTime = reporter.LastError()
+			status[i].LastSuccessTime = reporter.LastSuccess()
+		}
+	}
+	return status
+}
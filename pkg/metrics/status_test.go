@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type statusCollector struct {
+	testCollector
+	ReconcileStatus
+}
+
+func TestNewReconciler(t *testing.T) {
+	status := &ReconcileStatus{}
+	var reconcileErr error
+	reconciler := NewReconciler(status, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, reconcileErr
+	}))
+
+	_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	require.NoError(t, err)
+	lastError, _ := status.LastError()
+	require.Empty(t, lastError)
+	lastSuccess := status.LastSuccess()
+	require.False(t, lastSuccess.IsZero())
+
+	reconcileErr = fmt.Errorf("failed")
+	_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	require.Equal(t, reconcileErr, err)
+	require.Equal(t, lastSuccess, status.LastSuccess())
+
+	// The last error is kept after a successful reconcile
+	reconcileErr = nil
+	_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	require.NoError(t, err)
+	lastError, lastErrorTime := status.LastError()
+	require.Equal(t, "failed", lastError)
+	require.False(t, lastErrorTime.IsZero())
+}
+
+func TestNewReconciler_Disabled(t *testing.T) {
+	status := &ReconcileStatus{}
+	reconciles := 0
+	// The reconciler re-checks its object periodically, like the drain of a machine
+	recheck := reconcile.Result{RequeueAfter: 30 * time.Second}
+	reconciler := NewReconciler(status, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		reconciles++
+		return recheck, nil
+	}))
+
+	result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	require.NoError(t, err)
+	require.Equal(t, recheck, result)
+	require.Equal(t, 1, reconciles)
+
+	// The pending re-check is postponed instead of dropped while disabled
+	status.SetEnabled(false)
+	for i := 0; i < 3; i++ {
+		result, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		require.NoError(t, err)
+		require.Equal(t, reconcile.Result{RequeueAfter: DisabledRequeueInterval}, result)
+	}
+	require.Equal(t, 1, reconciles)
+
+	// Once enabled, the postponed request is reconciled and the re-checks resume
+	status.SetEnabled(true)
+	result, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+	require.NoError(t, err)
+	require.Equal(t, recheck, result)
+	require.Equal(t, 2, reconciles)
+}
+
+func TestReconcileStatus_Resync(t *testing.T) {
+	status := &ReconcileStatus{}
+	// Resyncing without a source does nothing
+	status.Resync()
+
+	object := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "name"}}
+	require.NotNil(t, status.ResyncSource(object))
+	status.Resync()
+	// The previous resync is still pending
+	status.Resync()
+	require.Len(t, status.resyncEvents, 1)
+	require.Equal(t, object, (<-status.resyncEvents).Object)
+}
+
+func TestRegistry_Resync(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	collector := &statusCollector{testCollector: testCollector{name: "test", desc: NewDesc("test", "test gauge")}}
+	collector.ResyncSource(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "name"}})
+	registry.MustRegister(collector)
+
+	registry.aggregate(time.Now())
+	require.Len(t, collector.resyncEvents, 1)
+	<-collector.resyncEvents
+
+	// Disabled collectors are not resynced until they get enabled again
+	require.NoError(t, registry.SetEnabled("test", false))
+	registry.aggregate(time.Now())
+	require.Empty(t, collector.resyncEvents)
+	require.NoError(t, registry.SetEnabled("test", true))
+	require.Len(t, collector.resyncEvents, 1)
+}
+
+func TestRegistry_Status(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	failing := &statusCollector{testCollector: testCollector{name: "failing", desc: NewDesc("failing", "test gauge")}}
+	failing.RecordError(fmt.Errorf("failed"))
+	registry.MustRegister(newTestCollector("plain"), failing)
+
+	status := registry.Status()
+	require.Len(t, status, 2)
+	require.Equal(t, CollectorStatus{Name: "plain", Active: true}, status[0])
+	require.Equal(t, "failing", status[1].Name)
+	require.Equal(t, "failed", status[1].LastError)
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const lastTransitionSuffix = "_last_transition_timestamp_seconds"
+
+// NewTransitionDesc returns the description of the companion metric exposing when the
+// boolean metric name last changed its value
+func NewTransitionDesc(name string, variableLabels ...string) *prometheus.Desc {
+	return NewDesc(
+		name+lastTransitionSuffix,
+		"Unix timestamp of the last change of "+name,
+		variableLabels...,
+	)
+}
+
+// BoolTransition tracks a boolean signal together with the time its value last changed.
+// The zero value has not observed the signal yet. It is not safe for concurrent use and
+// is meant to be guarded by the lock of the owning collector.
+type BoolTransition struct {
+	value          bool
+	observed       bool
+	lastTransition time.Time
+}
+
+// Set records the current value of the signal. The transition time only moves when the
+// signal is observed for the first time or when its value differs from the previous one.
+func (t *BoolTransition) Set(value bool, now time.Time) {
+	if t.observed && t.value == value {
+		return
+	}
+	t.value = value
+	t.observed = true
+	t.lastTransition = now
+}
+
+// Value returns the last recorded value of the signal, false if it was never observed
+func (t *BoolTransition) Value() bool {
+	return t.value
+}
+
+// LastTransition returns when the signal last changed and whether it was observed at all
+func (t *BoolTransition) LastTransition() (time.Time, bool) {
+	return t.lastTransition, t.observed
+}
+
+// Reset forgets the signal, the next Set counts as a transition again
+func (t *BoolTransition) Reset() {
+	*t = BoolTransition{}
+}
+
+// Collect emits the last transition timestamp for desc once the signal has been observed
+func (t *BoolTransition) Collect(ch chan<- prometheus.Metric, desc *prometheus.Desc, labelValues ...string) {
+	if !t.observed {
+		return
+	}
+	ch <- NewGauge(desc, float64(t.lastTransition.Unix()), labelValues...)
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type transitionCollector struct {
+	desc       *prometheus.Desc
+	transition BoolTransition
+}
+
+func (c *transitionCollector) Describe(ch chan<- *prometheus.Desc) { ch <- c.desc }
+func (c *transitionCollector) Collect(ch chan<- prometheus.Metric) {
+	c.transition.Collect(ch, c.desc, "cluster-id")
+}
+
+func TestBoolTransition(t *testing.T) {
+	first := time.Unix(1000, 0)
+	second := time.Unix(2000, 0)
+	third := time.Unix(3000, 0)
+
+	for _, tc := range []struct {
+		name     string
+		values   []bool
+		expected time.Time
+	}{
+		{
+			name:     "first observation is a transition",
+			values:   []bool{true},
+			expected: first,
+		},
+		{
+			name:     "unchanged value keeps the transition time",
+			values:   []bool{true, true, true},
+			expected: first,
+		},
+		{
+			name:     "changed value moves the transition time",
+			values:   []bool{true, false},
+			expected: second,
+		},
+		{
+			name:     "flip back moves the transition time",
+			values:   []bool{false, true, true},
+			expected: second,
+		},
+		{
+			name:     "every change is recorded",
+			values:   []bool{false, true, false},
+			expected: third,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var transition BoolTransition
+			for i, v := range tc.values {
+				transition.Set(v, []time.Time{first, second, third}[i])
+			}
+			lastTransition, observed := transition.LastTransition()
+			require.True(t, observed)
+			require.Equal(t, tc.expected, lastTransition)
+		})
+	}
+}
+
+func TestBoolTransition_Collect(t *testing.T) {
+	collector := &transitionCollector{desc: NewTransitionDesc("test_enabled", ClusterIDLabel)}
+	require.Equal(t, 0, testutil.CollectAndCount(collector))
+
+	collector.transition.Set(true, time.Unix(1000, 0))
+	expected := `
+# HELP test_enabled_last_transition_timestamp_seconds Unix timestamp of the last change of test_enabled
+# TYPE test_enabled_last_transition_timestamp_seconds gauge
+test_enabled_last_transition_timestamp_seconds{_id="cluster-id",name="osd_exporter"} 1000
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	collector.transition.Reset()
+	require.Equal(t, 0, testutil.CollectAndCount(collector))
+}
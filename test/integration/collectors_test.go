@@ -0,0 +1,182 @@
+// Package integration tests the collectors of every controller together with the metrics registry
+package integration
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/osd-metrics-exporter/controllers/certinventory"
+	"github.com/openshift/osd-metrics-exporter/controllers/configmap"
+	"github.com/openshift/osd-metrics-exporter/controllers/cpms"
+	"github.com/openshift/osd-metrics-exporter/controllers/group"
+	"github.com/openshift/osd-metrics-exporter/controllers/imagemirror"
+	"github.com/openshift/osd-metrics-exporter/controllers/imagepull"
+	"github.com/openshift/osd-metrics-exporter/controllers/limited_support"
+	"github.com/openshift/osd-metrics-exporter/controllers/machine"
+	"github.com/openshift/osd-metrics-exporter/controllers/oauth"
+	"github.com/openshift/osd-metrics-exporter/controllers/proxy"
+	"github.com/openshift/osd-metrics-exporter/controllers/pullsecret"
+	"github.com/openshift/osd-metrics-exporter/pkg/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stressClusterId  = "cluster-id"
+	stressIterations = 200
+)
+
+// TestCollectorsConcurrentAccess drives every setter of every collector while the
+// registry aggregates and gets scraped. It is meant to be run with -race.
+func TestCollectorsConcurrentAccess(t *testing.T) {
+	registry := metrics.NewRegistry(time.Millisecond)
+	certInventoryCollector := certinventory.NewCollector()
+	configMapCollector := configmap.NewCollector()
+	cpmsCollector := cpms.NewCollector()
+	groupCollector := group.NewCollector(stressClusterId)
+	imageMirrorCollector := imagemirror.NewCollector()
+	imagePullCollector := imagepull.NewCollector()
+	limitedSupportCollector := limited_support.NewCollector(stressClusterId)
+	machineCollector := machine.NewCollector()
+	oauthCollector := oauth.NewCollector()
+	proxyCollector := proxy.NewCollector()
+	pullSecretCollector := pullsecret.NewCollector()
+	registry.MustRegister(
+		certInventoryCollector,
+		configMapCollector,
+		cpmsCollector,
+		groupCollector,
+		imageMirrorCollector,
+		imagePullCollector,
+		limitedSupportCollector,
+		machineCollector,
+		oauthCollector,
+		proxyCollector,
+		pullSecretCollector,
+	)
+	done := registry.Run()
+	defer close(done)
+
+	setters := []func(i int){
+		func(i int) {
+			cert := &x509.Certificate{
+				Subject:  pkix.Name{CommonName: fmt.Sprintf("subject-%d", i%3)},
+				NotAfter: time.Unix(int64(i), 0),
+			}
+			certInventoryCollector.SetCertificateReferences(stressClusterId, []certinventory.Reference{{
+				Resource:     "oauth/cluster",
+				FieldPath:    fmt.Sprintf("spec.identityProviders[%d].openID.ca", i%3),
+				Valid:        i%2 == 0,
+				Certificates: []*x509.Certificate{cert},
+			}})
+		},
+		func(i int) {
+			cert := &x509.Certificate{
+				Subject:  pkix.Name{CommonName: fmt.Sprintf("subject-%d", i%3)},
+				NotAfter: time.Unix(int64(i), 0),
+			}
+			bundle := &configmap.Bundle{Certificates: []*x509.Certificate{cert}, ValidBlocks: 1}
+			if i%2 == 0 {
+				bundle.InvalidBlocks = []configmap.InvalidBlock{{Reason: "malformed_pem"}}
+			}
+			configMapCollector.SetClusterProxyCA(stressClusterId, bundle)
+			if i%5 == 0 {
+				configMapCollector.DeleteClusterProxyCA()
+			}
+		},
+		func(i int) {
+			cpmsCollector.SetCPMSEnabled(stressClusterId, fmt.Sprintf("type-%d", i%3), i%2 == 0)
+		},
+		func(i int) {
+			groupCollector.SetClusterAdmin(stressClusterId, i%2 == 0)
+		},
+		func(i int) {
+			imageMirrorCollector.SetMirrors(stressClusterId, imagemirror.Mirrors{
+				Sets:        map[imagemirror.Kind]int{imagemirror.KindImageDigestMirrorSet: i % 3},
+				Sources:     map[imagemirror.Kind][]string{imagemirror.KindImageDigestMirrorSet: {fmt.Sprintf("source-%d", i%3)}},
+				Credentials: map[string]bool{fmt.Sprintf("mirror-%d", i%3): i%2 == 0},
+			})
+		},
+		func(i int) {
+			imagePullCollector.SetFailures(stressClusterId, map[imagepull.Failure]int{
+				{Registry: fmt.Sprintf("registry-%d", i%3), Reason: imagepull.ReasonAuth, InPullSecret: i%2 == 0}: i,
+			})
+		},
+		func(i int) {
+			limitedSupportCollector.SetLimitedSupport(stressClusterId, i%2 == 0)
+		},
+		func(i int) {
+			machineName := fmt.Sprintf("machine-%d", i%3)
+			machineCollector.SetFailingDrainPodsForMachine(machineName, map[string]string{"pod": "namespace"}, "node")
+			if i%2 == 0 {
+				machineCollector.RemoveMachineMetrics(machineName)
+			}
+		},
+		func(i int) {
+			name := fmt.Sprintf("oauth-%d", i%3)
+			oauthCollector.SetOAuthIDP(name, "", []configv1.IdentityProvider{{
+				IdentityProviderConfig: configv1.IdentityProviderConfig{Type: configv1.IdentityProviderTypeGitHub},
+			}})
+			oauthCollector.SetOAuthIDPReferences(name, "", []oauth.ReferenceStatus{
+				{IDP: fmt.Sprintf("idp-%d", i%3), Field: "clientSecret", Valid: i%2 == 0},
+			})
+			oauthCollector.SetOAuthSessionPolicy(name, "", configv1.OAuthSpec{
+				TokenConfig: configv1.TokenConfig{AccessTokenMaxAgeSeconds: int32(i)},
+			})
+			oauthCollector.SetOAuthIDPHTPasswd(name, "", []oauth.HTPasswdInventory{
+				{IDP: fmt.Sprintf("idp-%d", i%3), Users: i, WeakHashes: map[string]int{"sha1": i % 2}},
+			})
+			if i%2 == 0 {
+				oauthCollector.DeleteOAuthIDP(name, "")
+			}
+		},
+		func(i int) {
+			proxyCollector.SetClusterID(stressClusterId)
+			proxyCollector.SetClusterProxy(stressClusterId, "1", "0", "0", i%2)
+		},
+		func(i int) {
+			pullSecretCollector.SetPullSecretValid(stressClusterId, i%2 == 0, fmt.Sprintf("reason-%d", i%3))
+			pullSecretCollector.SetEntries(stressClusterId, &pullsecret.Entries{
+				Registries: []pullsecret.RegistryPresence{
+					{Registry: fmt.Sprintf("registry-%d", i%3), Required: true, Present: i%2 == 0},
+				},
+				InvalidEntries: map[string]int{pullsecret.ReasonInvalidBase64: i % 3},
+			})
+			pullSecretCollector.SetProbeResults(stressClusterId, []pullsecret.ProbeResult{
+				{Registry: fmt.Sprintf("registry-%d", i%3), OK: i%2 == 0, LastProbe: time.Unix(int64(i), 0)},
+			})
+			pullSecretCollector.SetChanges(stressClusterId, &pullsecret.Changes{
+				Registries: i % 3,
+				LastChange: time.Unix(int64(i), 0),
+				Rotations:  i,
+			})
+		},
+	}
+
+	var wg sync.WaitGroup
+	for _, set := range setters {
+		// Two writers per domain to also catch races within a single collector
+		for w := 0; w < 2; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < stressIterations; i++ {
+					set(i)
+				}
+			}()
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < stressIterations; i++ {
+			_, err := registry.GetPrometheusRegistry().Gather()
+			require.NoError(t, err)
+		}
+	}()
+	wg.Wait()
+}